@@ -1,30 +1,72 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+	// The runtime image has no zoneinfo, so the zone database is embedded for timeZone.
+	_ "time/tzdata"
 	"yadro-biathlon/internal/config"
 	event "yadro-biathlon/internal/events"
+	"yadro-biathlon/internal/models"
 	process "yadro-biathlon/internal/processor"
 )
 
+// followPollInterval is how often a followed events file is checked for new lines.
+const followPollInterval = 500 * time.Millisecond
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		formatEvents(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		printConfig(os.Args[3:])
+		return
+	}
+
 	//Define command-line flags
 	saveLogs := flag.String("save_logs", "", "save logs to file")
-	eventsFile := flag.String("events_file", "./internal/config/events", "file with events")
+	eventsFile := flag.String("events_file", "./internal/config/events", "file with events ('-' reads from stdin)")
+	lenient := flag.Bool("lenient", false, "skip malformed event lines and report them instead of stopping")
+	strict := flag.Bool("strict", false, "stop at the first event that is not allowed in its competitor's state")
+	showTargets := flag.Bool("show_targets", false, "show hit and missed targets of every stage in the report, e.g. x.xx.")
+	showDistance := flag.Bool("show_distance", false, "show the total distance and average speed of every finisher in the report")
+	showStages := flag.Bool("show_stages", false, "append every competitor's firing range visits to the report")
+	rangeTimes := flag.Bool("range_times", false, "append a ranking by time on the firing ranges to the report")
+	eventsFormat := flag.String("events_format", "auto", "format of the events file: auto, text or json")
+	follow := flag.Bool("follow", false, "keep reading the events file as it grows until interrupted")
 	configFile := flag.String("config_file", "./internal/config/config.json", "file with config")
 	resultFile := flag.String("result_file", "resultingTable", "file with results")
+	saveEvents := flag.String("save_events", "", "save all events, including disqualifications and finishes, to file")
+	overrides := configOverrides(flag.CommandLine)
 	flag.Parse()
 
 	// 'conf' holds race parameters (laps, lap length, penalty length, etc.) and timing settings.
-	conf, err := config.LoadConfig(*configFile)
+	// Values from the file are overridden by BIATHLON_* environment variables and then by flags.
+	layered, err := config.Load(*configFile, os.LookupEnv, overrides)
 	if err != nil {
 		fmt.Printf("Error loading configuration(%s): %v\n", *configFile, err)
 		return
 	}
+	conf := layered.Config
 
 	//'processor' manages state, logs events, and generates the race report.
-	processor := process.NewEventProcessor(conf)
+	processor, err := process.NewEventProcessor(conf)
+	if err != nil {
+		fmt.Printf("Error setting up the race: %v\n", err)
+		return
+	}
+	processor.Strict = *strict
+	processor.ShowTargets = *showTargets
+	processor.ShowDistance = *showDistance
+	processor.ShowStages = *showStages
+	processor.ShowRangeTimes = *rangeTimes
 
 	if *saveLogs != "" {
 		err = processor.EnableLogFile(*saveLogs)
@@ -33,14 +75,58 @@ func main() {
 		}
 	}
 
-	events, err := event.LoadEvents(*eventsFile)
+	format, err := event.ParseFormat(*eventsFormat)
 	if err != nil {
 		fmt.Printf("Error loading events: %v\n", err)
 		return
 	}
 
-	// Process all events
-	processor.ProcessEvents(events)
+	var input io.Reader = os.Stdin
+	live := *eventsFile == "-" || *follow
+	if *eventsFile != "-" {
+		file, err := os.Open(*eventsFile)
+		if err != nil {
+			fmt.Printf("Error loading events: %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		input = file
+		if *follow {
+			// Stop following the file on Ctrl+C and still produce the report. Other runs keep
+			// the default handling, so an interrupted read from stdin ends the process.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			input = event.Follow(ctx, file, followPollInterval)
+		}
+	}
+
+	// In live mode the result file holds the current standings after every event.
+	var update func(models.Event)
+	if live {
+		update = func(models.Event) {
+			if err := os.WriteFile(*resultFile, []byte(processor.Standings()), 0644); err != nil {
+				fmt.Printf("Error saving standings: %v\n", err)
+			}
+		}
+	}
+
+	reader := event.NewReader(input)
+	reader.Lenient = *lenient
+	reader.Format = format
+	reader.Config = &conf
+
+	// Process events as they are read
+	err = processor.ProcessStream(reader, update)
+	if err != nil {
+		fmt.Printf("Error loading events: %v\n", err)
+		return
+	}
+
+	// Let officials see which lines were left out of the results
+	if summary := event.Summary(reader.Rejected()); summary != "" {
+		processor.WriteLog(strings.TrimSuffix(summary, "\n"))
+	}
 
 	// Generate and save the report to the result file
 	err = processor.SaveReport(*resultFile)
@@ -48,9 +134,152 @@ func main() {
 		fmt.Printf("Error saving report: %v\n", err)
 	}
 
+	// Write the processed race, outgoing events included, so other tools can read it back
+	if *saveEvents != "" {
+		err = saveEventsFile(*saveEvents, conf, processor.Events)
+		if err != nil {
+			fmt.Printf("Error saving events: %v\n", err)
+		}
+	}
+
 	fmt.Println("\nProcessing completed successfully")
 	if *saveLogs != "" {
 		fmt.Printf("Logs saved to: %s\n", *saveLogs)
 	}
 	fmt.Printf("Report saved to: %s\n", *resultFile)
+	if *saveEvents != "" {
+		fmt.Printf("Events saved to: %s\n", *saveEvents)
+	}
+}
+
+// saveEventsFile writes events to filename in canonical event file format,
+// with times in the layout and zone of conf.
+func saveEventsFile(filename string, conf config.Configuration, events []models.Event) error {
+	codec, err := event.NewCodec(conf)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return codec.WriteEvents(file, events)
+}
+
+// formatEvents implements the "fmt" command: it rewrites an events file canonically,
+// sorted by time with normalized spacing, in the time layout, precision and zone of the configuration.
+// It accepts the same configuration flags as a race run.
+// Usage: main fmt [-w] [-events_format=auto|text|json] [-config_file=file] [-timeLayout=... ...] file
+func formatEvents(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write the result back to the file instead of stdout")
+	eventsFormat := flags.String("events_format", "auto", "format of the events file: auto, text or json")
+	configFile := flags.String("config_file", "./internal/config/config.json", "file with config")
+	overrides := configOverrides(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: fmt [-w] [-events_format=auto|text|json] [-config_file=file] <events_file>")
+		return
+	}
+	filename := flags.Arg(0)
+
+	layered, err := config.Load(*configFile, os.LookupEnv, overrides)
+	if err != nil {
+		fmt.Printf("Error loading configuration(%s): %v\n", *configFile, err)
+		return
+	}
+	conf := layered.Config
+	codec, err := event.NewCodec(conf)
+	if err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+		return
+	}
+	date, err := conf.RaceDate()
+	if err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+		return
+	}
+
+	format, err := event.ParseFormat(*eventsFormat)
+	if err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+		return
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+		return
+	}
+	reader := event.NewReader(file)
+	reader.Format = format
+	reader.Config = &conf
+
+	var events []models.Event
+	for {
+		e, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			fmt.Printf("Error formatting events: %v\n", err)
+			return
+		}
+		events = append(events, e)
+	}
+	file.Close()
+
+	// Keep events recorded after midnight behind the ones before it when sorting.
+	event.PlaceEvents(events, date)
+
+	if !*write {
+		if err := codec.WriteEvents(os.Stdout, events); err != nil {
+			fmt.Printf("Error formatting events: %v\n", err)
+		}
+		return
+	}
+
+	var formatted strings.Builder
+	if err := codec.WriteEvents(&formatted, events); err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(filename, []byte(formatted.String()), 0644); err != nil {
+		fmt.Printf("Error formatting events: %v\n", err)
+	}
+}
+
+// configOverrides registers a flag for every configuration field on flags, named like
+// the JSON field, and returns the values given on the command line keyed by field name.
+func configOverrides(flags *flag.FlagSet) map[string]string {
+	overrides := make(map[string]string)
+	for _, name := range config.Fields() {
+		usage := fmt.Sprintf("override %s from the config file and %s", name, config.EnvName(name))
+		flags.Func(name, usage, func(value string) error {
+			overrides[name] = value
+			return nil
+		})
+	}
+	return overrides
+}
+
+// printConfig implements the "config print" command: it shows the effective configuration
+// and where each value came from. It accepts the same configuration flags as a race run.
+// Usage: main config print [-config_file=file] [-laps=3 ...]
+func printConfig(args []string) {
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	configFile := flags.String("config_file", "./internal/config/config.json", "file with config")
+	overrides := configOverrides(flags)
+	flags.Parse(args)
+
+	layered, err := config.Load(*configFile, os.LookupEnv, overrides)
+	fmt.Print(layered)
+	if err != nil {
+		fmt.Printf("Error loading configuration(%s): %v\n", *configFile, err)
+	}
 }
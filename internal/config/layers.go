@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Source tells which layer an effective configuration value came from.
+type Source string
+
+// Configuration layers in increasing order of precedence:
+// built-in defaults, the JSON file, environment variables and command-line flags.
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// EnvPrefix starts the environment variable of every field, e.g. BIATHLON_LAPS or BIATHLON_LAP_LEN.
+const EnvPrefix = "BIATHLON_"
+
+// Layered is an effective configuration together with the source of each field.
+type Layered struct {
+	Config  Configuration
+	Sources map[string]Source // keyed by JSON field name
+}
+
+// Load builds the effective configuration from the JSON file, then environment variables
+// read through lookupEnv (usually os.LookupEnv), then overrides given as command-line flags,
+// each layer taking precedence over the previous ones. Overrides are keyed by JSON field name.
+// The result is validated once all layers are applied.
+func Load(filename string, lookupEnv func(string) (string, bool), overrides map[string]string) (Layered, error) {
+	layered := Layered{Sources: make(map[string]Source)}
+	for _, name := range Fields() {
+		layered.Sources[name] = SourceDefault
+	}
+
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return layered, err
+		}
+		if err := decodeStrict(data, &layered.Config); err != nil {
+			return layered, err
+		}
+
+		var present map[string]json.RawMessage
+		if err := json.Unmarshal(data, &present); err != nil {
+			return layered, err
+		}
+		for name := range present {
+			layered.Sources[name] = SourceFile
+		}
+	}
+
+	for _, name := range Fields() {
+		value, ok := lookupEnv(EnvName(name))
+		if !ok {
+			continue
+		}
+		if err := layered.Config.Set(name, value); err != nil {
+			return layered, fmt.Errorf("%s: %v", EnvName(name), err)
+		}
+		layered.Sources[name] = SourceEnv
+	}
+
+	for name, value := range overrides {
+		if err := layered.Config.Set(name, value); err != nil {
+			return layered, fmt.Errorf("-%s: %v", name, err)
+		}
+		layered.Sources[name] = SourceFlag
+	}
+
+	return layered, layered.Config.Validate()
+}
+
+// Fields returns the JSON names of all configuration fields in declaration order.
+func Fields() []string {
+	t := reflect.TypeOf(Configuration{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, jsonName(t.Field(i)))
+	}
+	return names
+}
+
+// EnvName returns the environment variable that overrides a field, e.g. lapLen -> BIATHLON_LAP_LEN.
+func EnvName(field string) string {
+	var name strings.Builder
+	name.WriteString(EnvPrefix)
+	for i, r := range field {
+		if unicode.IsUpper(r) && i > 0 {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+	return name.String()
+}
+
+// Set assigns a field by JSON name from its text form. String fields take the text as is;
+// other fields take it as a JSON value, e.g. "3" for laps.
+func (c *Configuration) Set(field, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) != field {
+			continue
+		}
+
+		target := v.Field(i)
+		if target.Kind() == reflect.String {
+			target.SetString(value)
+			return nil
+		}
+		if err := json.Unmarshal([]byte(value), target.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid value %q for %s", value, field)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown configuration field %q", field)
+}
+
+// String lists every field with its effective value and source, one per line.
+// Fields left to their default show the value the race runs with.
+func (l Layered) String() string {
+	v := reflect.ValueOf(l.Config.Effective())
+	names := Fields()
+
+	values := make([]string, len(names))
+	nameWidth, valueWidth := 0, 0
+	for i, name := range names {
+		value, _ := json.Marshal(v.Field(i).Interface())
+		values[i] = string(value)
+		nameWidth = max(nameWidth, len(name))
+		valueWidth = max(valueWidth, len(values[i]))
+	}
+
+	var out strings.Builder
+	for i, name := range names {
+		source := string(l.Sources[name])
+		if l.Sources[name] == SourceEnv {
+			source += " " + EnvName(name)
+		}
+		out.WriteString(fmt.Sprintf("%-*s  %-*s  (%s)\n", nameWidth, name, valueWidth, values[i], source))
+	}
+	return out.String()
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func decodeStrict(data []byte, config *Configuration) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
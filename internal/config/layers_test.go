@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T) string {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configContent := `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2,
+		"start": "10:00:00.000", "startDelta": "00:01:30"}`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	return configPath
+}
+
+func TestLoadLayers(t *testing.T) {
+	env := map[string]string{
+		"BIATHLON_LAPS":      "3",
+		"BIATHLON_LAP_LEN":   "3300",
+		"BIATHLON_TIME_ZONE": "UTC",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	layered, err := Load(writeTestConfig(t), lookupEnv, map[string]string{"laps": "4"})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if layered.Config.Laps != 4 {
+		t.Errorf("Expected the flag to win with Laps=4, got %d", layered.Config.Laps)
+	}
+	if layered.Config.LapLen != 3300 {
+		t.Errorf("Expected the environment to set LapLen=3300, got %d", layered.Config.LapLen)
+	}
+	if layered.Config.PenaltyLen != 150 {
+		t.Errorf("Expected PenaltyLen=150 from the file, got %d", layered.Config.PenaltyLen)
+	}
+
+	expected := map[string]Source{
+		"laps":       SourceFlag,
+		"lapLen":     SourceEnv,
+		"timeZone":   SourceEnv,
+		"penaltyLen": SourceFile,
+		"date":       SourceDefault,
+	}
+	for name, source := range expected {
+		if layered.Sources[name] != source {
+			t.Errorf("Expected %s from %s, got %s", name, source, layered.Sources[name])
+		}
+	}
+
+	printed := layered.String()
+	if !strings.Contains(printed, "(env BIATHLON_LAP_LEN)") || !strings.Contains(printed, "(flag)") {
+		t.Errorf("Unexpected config print output:\n%s", printed)
+	}
+}
+
+func TestLoadLayersErrors(t *testing.T) {
+	configPath := writeTestConfig(t)
+
+	_, err := Load(configPath, noEnv, map[string]string{"laps": "three"})
+	if err == nil || !strings.Contains(err.Error(), "-laps") {
+		t.Errorf("Expected invalid flag value error, got: %v", err)
+	}
+
+	_, err = Load(configPath, noEnv, map[string]string{"laps": "0"})
+	if err == nil || !strings.Contains(err.Error(), "laps: must be positive") {
+		t.Errorf("Expected validation of overridden values, got: %v", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"laps":          "BIATHLON_LAPS",
+		"lapLen":        "BIATHLON_LAP_LEN",
+		"timePrecision": "BIATHLON_TIME_PRECISION",
+	}
+	for field, expected := range tests {
+		if name := EnvName(field); name != expected {
+			t.Errorf("EnvName(%s): expected %s, got %s", field, expected, name)
+		}
+	}
+}
+
+func TestPrintShowsEffectiveDefaults(t *testing.T) {
+	layered, err := Load(writeTestConfig(t), noEnv, map[string]string{"format": FormatRelay, "teams": `[{"id": 1, "members": [1, 2]}]`})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	printed := layered.String()
+	for _, want := range []string{`"relay"`, `"15:04:05.000"`, `"00:01:00.000"`} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Expected %s in config print output:\n%s", want, printed)
+		}
+	}
+	for name, value := range map[string]string{"timePrecision": "3", "targets": "5", "rounds": "8"} {
+		if !regexp.MustCompile(`(?m)^` + name + ` +` + value + ` +\(default\)$`).MatchString(printed) {
+			t.Errorf("Expected %s %s (default) in config print output:\n%s", name, value, printed)
+		}
+	}
+}
@@ -1,36 +1,250 @@
 package config
 
-import (
-	"encoding/json"
-	"os"
-)
+import "time"
 
-// TimeFormat defines the layout for parsing and formatting timestamps in events.
+// TimeFormat defines the default layout for parsing and formatting timestamps in events.
+// TimePrecision is the default number of fractional second digits in logs and reports.
+// DateFormat defines the layout of the race date.
 const (
-	TimeFormat = "15:04:05.000"
+	TimeFormat    = "15:04:05.000"
+	TimePrecision = 3
+	DateFormat    = "2006-01-02"
 )
 
 // Configuration holds race parameters, loaded from a JSON file.
 type Configuration struct {
-	Laps        int    `json:"laps"`
-	LapLen      int    `json:"lapLen"`
+	Laps   int `json:"laps"`
+	LapLen int `json:"lapLen"` // length of every lap, unless LapLens is set
+	// LapLens lists the length of every lap when the laps differ.
+	LapLens     []int  `json:"lapLens,omitempty"`
 	PenaltyLen  int    `json:"penaltyLen"`
 	FiringLines int    `json:"firingLines"`
 	Start       string `json:"start"`
 	StartDelta  string `json:"startDelta"`
+	// Date (YYYY-MM-DD) is the day the race starts on; event times are placed on it
+	// and on the following days when the clock passes midnight.
+	Date string `json:"date,omitempty"`
+	// ReorderWindow (HH:MM:SS.mmm) is how long events are held back to be put in time order,
+	// at most MaxLateness; empty disables reordering.
+	ReorderWindow string `json:"reorderWindow,omitempty"`
+	// TimeLayout is the Go layout of event times, e.g. "15:04:05,000"; it defaults to TimeFormat.
+	TimeLayout string `json:"timeLayout,omitempty"`
+	// TimePrecision is the number (1-9) of fractional second digits written to logs, reports
+	// and event files; it defaults to TimePrecision.
+	TimePrecision int `json:"timePrecision,omitempty"`
+	// TimeZone is the IANA zone of the venue; it defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Format is the race format, one of Formats; it defaults to FormatSprint.
+	Format string `json:"format,omitempty"`
+	// MissPenalty (HH:MM:SS.mmm) is the time added per missed target in formats that penalise
+	// misses with time instead of penalty loops; it defaults to DefaultMissPenalty.
+	MissPenalty string `json:"missPenalty,omitempty"`
+	// PriorResults is the results file of the race a pursuit is started from.
+	PriorResults string `json:"priorResults,omitempty"`
+	// HandicapCap (HH:MM:SS.mmm) is the longest pursuit start gap; longer gaps are cut to it.
+	HandicapCap string `json:"handicapCap,omitempty"`
+	// Teams are the relay teams, required by and only used in FormatRelay.
+	Teams []Team `json:"teams,omitempty"`
+	// Targets and Rounds are the targets and rounds at every shooting stage; rounds beyond the
+	// targets are spare rounds loaded by hand. They default to DefaultTargets and one round per
+	// target, plus DefaultRelaySpareRounds in a relay.
+	Targets int `json:"targets,omitempty"`
+	Rounds  int `json:"rounds,omitempty"`
+	// Stages overrides Targets and Rounds stage by stage in shooting order.
+	Stages []Stage `json:"stages,omitempty"`
+	// SkippedLoops, one of the SkippedLoops* consequences, turns on the check that finishers
+	// skied every penalty loop they owed.
+	SkippedLoops string `json:"skippedLoops,omitempty"`
+	// SkippedLoopPenalty (HH:MM:SS.mmm) is the time added per loop not skied under
+	// SkippedLoopsPenalty; it defaults to DefaultSkippedLoopPenalty.
+	SkippedLoopPenalty string `json:"skippedLoopPenalty,omitempty"`
+}
+
+// Stage sets the targets and rounds of one shooting stage; zero keeps the race-wide value.
+type Stage struct {
+	Targets int `json:"targets,omitempty"`
+	Rounds  int `json:"rounds,omitempty"`
+}
+
+// DefaultTargets is the number of targets at a shooting stage.
+// DefaultRelaySpareRounds is the number of spare rounds per stage in a relay.
+const (
+	DefaultTargets          = 5
+	DefaultRelaySpareRounds = 3
+)
+
+// Team is a relay team; Members lists its competitors in the order they ski the legs.
+type Team struct {
+	ID      int   `json:"id"`
+	Members []int `json:"members"`
 }
 
-// LoadConfig reads and parses a JSON configuration file into Configuration.
-// The JSON must match the struct tags, otherwise Decode will return an error.
+// MaxLateness is how far an event time may fall behind the latest one and still be taken
+// as a late event of the same day rather than a time after midnight.
+const MaxLateness = time.Hour
+
+// DefaultMissPenalty is the classic time penalty per miss of the individual race.
+const DefaultMissPenalty = time.Minute
+
+// Consequences accepted in Configuration.SkippedLoops for a finisher who skied fewer penalty loops
+// than they owed: the finisher is only flagged, gets a time penalty per loop, or is disqualified.
+const (
+	SkippedLoopsFlag       = "flag"
+	SkippedLoopsPenalty    = "penalty"
+	SkippedLoopsDisqualify = "disqualify"
+)
+
+// DefaultSkippedLoopPenalty is the time added per penalty loop not skied.
+const DefaultSkippedLoopPenalty = 2 * time.Minute
+
+// Race formats accepted in Configuration.Format.
+const (
+	FormatSprint     = "sprint"
+	FormatIndividual = "individual"
+	FormatPursuit    = "pursuit"
+	FormatMassStart  = "massStart"
+	FormatRelay      = "relay"
+)
+
+// Formats lists the supported race formats.
+var Formats = []string{FormatSprint, FormatIndividual, FormatPursuit, FormatMassStart, FormatRelay}
+
+// LoadConfig reads, parses and validates a JSON configuration file into Configuration.
+// The JSON must match the struct tags: unknown fields are rejected, and so is
+// any configuration Validate finds problems with. Use Load to apply overrides as well.
 func LoadConfig(filename string) (Configuration, error) {
-	var config Configuration
-	file, err := os.Open(filename)
+	layered, err := Load(filename, noEnv, nil)
+	return layered.Config, err
+}
+
+func noEnv(string) (string, bool) {
+	return "", false
+}
+
+// NoDate is the day clock-only times are parsed onto; it is the race date when none is configured.
+var NoDate = time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// RaceDate returns midnight of the configured race date in the venue's time zone,
+// or NoDate in that zone if no date is set.
+func (c Configuration) RaceDate() (time.Time, error) {
+	loc, err := c.Location()
 	if err != nil {
-		return config, err
+		return NoDate, err
+	}
+	if c.Date == "" {
+		return time.Date(0, time.January, 1, 0, 0, 0, 0, loc), nil
+	}
+	return time.ParseInLocation(DateFormat, c.Date, loc)
+}
+
+// Location returns the venue's time zone, UTC if none is configured.
+func (c Configuration) Location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.TimeZone)
+}
+
+// Layout returns the layout of event times.
+func (c Configuration) Layout() string {
+	if c.TimeLayout == "" {
+		return TimeFormat
+	}
+	return c.TimeLayout
+}
+
+// Precision returns the number of fractional second digits used when writing times.
+func (c Configuration) Precision() int {
+	if c.TimePrecision == 0 {
+		return TimePrecision
+	}
+	return c.TimePrecision
+}
+
+// RaceFormat returns the race format, FormatSprint if none is configured.
+func (c Configuration) RaceFormat() string {
+	if c.Format == "" {
+		return FormatSprint
+	}
+	return c.Format
+}
+
+// StageTargets returns the number of targets at a shooting stage, numbered from 0.
+func (c Configuration) StageTargets(stage int) int {
+	if stage >= 0 && stage < len(c.Stages) && c.Stages[stage].Targets > 0 {
+		return c.Stages[stage].Targets
+	}
+	if c.Targets > 0 {
+		return c.Targets
+	}
+	return DefaultTargets
+}
+
+// StageRounds returns the number of rounds at a shooting stage, numbered from 0.
+func (c Configuration) StageRounds(stage int) int {
+	if stage >= 0 && stage < len(c.Stages) && c.Stages[stage].Rounds > 0 {
+		return c.Stages[stage].Rounds
+	}
+	if c.Rounds > 0 {
+		return c.Rounds
+	}
+	if c.RaceFormat() == FormatRelay {
+		return c.StageTargets(stage) + DefaultRelaySpareRounds
+	}
+	return c.StageTargets(stage)
+}
+
+// MaxTargets returns the largest number of targets at any shooting stage.
+func (c Configuration) MaxTargets() int {
+	targets := c.StageTargets(len(c.Stages))
+	for stage := range c.Stages {
+		targets = max(targets, c.StageTargets(stage))
+	}
+	return targets
+}
+
+// Effective returns the configuration with the defaults the race runs with filled in,
+// e.g. the sprint format, UTC and 5 targets per stage. Settings whose absence turns a
+// feature off, like reorderWindow or date, stay empty.
+func (c Configuration) Effective() Configuration {
+	effective := c
+	effective.Format = c.RaceFormat()
+	effective.TimeLayout = c.Layout()
+	effective.TimePrecision = c.Precision()
+	if c.TimeZone == "" {
+		effective.TimeZone = time.UTC.String()
+	}
+	if c.MissPenalty == "" {
+		effective.MissPenalty = FormatDuration(DefaultMissPenalty)
+	}
+	if c.SkippedLoopPenalty == "" {
+		effective.SkippedLoopPenalty = FormatDuration(DefaultSkippedLoopPenalty)
 	}
-	defer file.Close()
 
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&config)
-	return config, err
+	effective.Targets = c.StageTargets(len(c.Stages))
+	effective.Rounds = c.StageRounds(len(c.Stages))
+	if len(c.Stages) > 0 {
+		effective.Stages = make([]Stage, len(c.Stages))
+		for stage := range c.Stages {
+			effective.Stages[stage] = Stage{Targets: c.StageTargets(stage), Rounds: c.StageRounds(stage)}
+		}
+	}
+	return effective
+}
+
+// LapLength returns the length of a lap, numbered from 1.
+func (c Configuration) LapLength(lap int) int {
+	if lap >= 1 && lap <= len(c.LapLens) {
+		return c.LapLens[lap-1]
+	}
+	return c.LapLen
+}
+
+// CourseLength returns the length of all laps together, without penalty loops.
+func (c Configuration) CourseLength() int {
+	length := 0
+	for lap := 1; lap <= c.Laps; lap++ {
+		length += c.LapLength(lap)
+	}
+	return length
 }
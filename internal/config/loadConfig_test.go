@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -52,3 +53,62 @@ func TestLoadConfigFileNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 }
+
+func TestRaceDate(t *testing.T) {
+	date, err := Configuration{Date: "2024-12-31"}.RaceDate()
+	if err != nil {
+		t.Fatalf("RaceDate failed: %v", err)
+	}
+	if !date.Equal(time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2024-12-31, got %v", date)
+	}
+
+	date, err = Configuration{}.RaceDate()
+	if err != nil || !date.Equal(NoDate) {
+		t.Errorf("Expected NoDate without a date, got %v (%v)", date, err)
+	}
+
+	if _, err := (Configuration{Date: "31.12.2024"}).RaceDate(); err == nil {
+		t.Error("Expected error for malformed date, got nil")
+	}
+}
+
+func TestStageTargetsAndRounds(t *testing.T) {
+	conf := Configuration{Targets: 4, Stages: []Stage{{Targets: 10, Rounds: 12}, {Rounds: 6}}}
+
+	tests := []struct {
+		stage, targets, rounds int
+	}{
+		{0, 10, 12},
+		{1, 4, 6},
+		{2, 4, 4},
+	}
+	for _, test := range tests {
+		if got := conf.StageTargets(test.stage); got != test.targets {
+			t.Errorf("Stage %d: expected %d targets, got %d", test.stage, test.targets, got)
+		}
+		if got := conf.StageRounds(test.stage); got != test.rounds {
+			t.Errorf("Stage %d: expected %d rounds, got %d", test.stage, test.rounds, got)
+		}
+	}
+	if conf.MaxTargets() != 10 {
+		t.Errorf("Expected at most 10 targets, got %d", conf.MaxTargets())
+	}
+
+	relay := Configuration{Format: FormatRelay}
+	if relay.StageTargets(0) != DefaultTargets || relay.StageRounds(0) != DefaultTargets+DefaultRelaySpareRounds {
+		t.Errorf("Expected 5 targets and 8 rounds in a relay, got %d and %d", relay.StageTargets(0), relay.StageRounds(0))
+	}
+}
+
+func TestLapLength(t *testing.T) {
+	conf := Configuration{Laps: 3, LapLen: 3300}
+	if conf.LapLength(2) != 3300 || conf.CourseLength() != 9900 {
+		t.Errorf("Expected 3300m laps and 9900m in total, got %d and %d", conf.LapLength(2), conf.CourseLength())
+	}
+
+	conf.LapLens = []int{3300, 3300, 2500}
+	if conf.LapLength(3) != 2500 || conf.CourseLength() != 9100 {
+		t.Errorf("Expected a 2500m last lap and 9100m in total, got %d and %d", conf.LapLength(3), conf.CourseLength())
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks the whole configuration and reports every problem at once.
+// Each problem is prefixed with the JSON name of the field it concerns.
+func (c Configuration) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{field}, args...)...))
+	}
+
+	if c.Laps <= 0 {
+		fail("laps", "must be positive, got %d", c.Laps)
+	}
+	if len(c.LapLens) == 0 && c.LapLen <= 0 {
+		fail("lapLen", "must be positive, got %d", c.LapLen)
+	}
+	if len(c.LapLens) > 0 && len(c.LapLens) != c.Laps {
+		fail("lapLens", "has %d lengths for %d laps", len(c.LapLens), c.Laps)
+	}
+	for i, length := range c.LapLens {
+		if length <= 0 {
+			fail("lapLens", "lap %d must have a positive length, got %d", i+1, length)
+		}
+	}
+	if c.PenaltyLen < 0 {
+		fail("penaltyLen", "must not be negative, got %d", c.PenaltyLen)
+	}
+	if c.FiringLines < 0 {
+		fail("firingLines", "must not be negative, got %d", c.FiringLines)
+	}
+
+	layoutErr := checkLayout(c.Layout())
+	if layoutErr != nil {
+		fail("timeLayout", "%v", layoutErr)
+	}
+	if c.TimePrecision < 0 || c.TimePrecision > 9 {
+		fail("timePrecision", "must be between 1 and 9, or 0 for the default, got %d", c.TimePrecision)
+	}
+	_, zoneErr := c.Location()
+	if zoneErr != nil {
+		fail("timeZone", "%v", zoneErr)
+	}
+
+	if c.Start == "" {
+		fail("start", "is required")
+	} else if layoutErr == nil && zoneErr == nil {
+		if _, err := c.StartTime(); err != nil {
+			fail("start", "%v", err)
+		}
+	}
+	if c.StartDelta == "" {
+		fail("startDelta", "is required")
+	} else if _, err := ParseDuration(c.StartDelta); err != nil {
+		fail("startDelta", "%v", err)
+	}
+	if c.Date != "" {
+		if _, err := time.Parse(DateFormat, c.Date); err != nil {
+			fail("date", "expected YYYY-MM-DD: %v", err)
+		}
+	}
+	if c.ReorderWindow != "" {
+		if window, err := ParseDuration(c.ReorderWindow); err != nil {
+			fail("reorderWindow", "%v", err)
+		} else if window > MaxLateness {
+			fail("reorderWindow", "longer than %v, later events would be taken as after midnight", MaxLateness)
+		}
+	}
+
+	if c.MissPenalty != "" {
+		if _, err := ParseDuration(c.MissPenalty); err != nil {
+			fail("missPenalty", "%v", err)
+		}
+	}
+	if c.HandicapCap != "" {
+		if _, err := ParseDuration(c.HandicapCap); err != nil {
+			fail("handicapCap", "%v", err)
+		}
+	}
+	switch c.SkippedLoops {
+	case "", SkippedLoopsFlag, SkippedLoopsPenalty, SkippedLoopsDisqualify:
+	default:
+		fail("skippedLoops", "unknown consequence %q, expected %s, %s or %s",
+			c.SkippedLoops, SkippedLoopsFlag, SkippedLoopsPenalty, SkippedLoopsDisqualify)
+	}
+	if c.SkippedLoopPenalty != "" {
+		if _, err := ParseDuration(c.SkippedLoopPenalty); err != nil {
+			fail("skippedLoopPenalty", "%v", err)
+		}
+	}
+	if c.PriorResults != "" && c.RaceFormat() != FormatPursuit {
+		fail("priorResults", "only applies to the %s format", FormatPursuit)
+	}
+	if c.RaceFormat() == FormatRelay && len(c.Teams) == 0 {
+		fail("teams", "are required in the %s format", FormatRelay)
+	}
+	if c.RaceFormat() != FormatRelay && len(c.Teams) > 0 {
+		fail("teams", "only apply to the %s format", FormatRelay)
+	}
+	teams := make(map[int]bool)
+	members := make(map[int]int)
+	for _, team := range c.Teams {
+		if teams[team.ID] {
+			fail("teams", "team %d is listed twice", team.ID)
+		}
+		teams[team.ID] = true
+		if len(team.Members) == 0 {
+			fail("teams", "team %d has no members", team.ID)
+		}
+		for _, id := range team.Members {
+			if other, ok := members[id]; ok {
+				fail("teams", "competitor %d is in team %d and team %d", id, other, team.ID)
+			}
+			members[id] = team.ID
+		}
+	}
+	if c.Targets < 0 {
+		fail("targets", "must not be negative, got %d", c.Targets)
+	}
+	if c.Rounds < 0 {
+		fail("rounds", "must not be negative, got %d", c.Rounds)
+	}
+	for stage := 0; stage <= len(c.Stages); stage++ {
+		field := "rounds"
+		if stage < len(c.Stages) {
+			field = fmt.Sprintf("stages[%d]", stage)
+			if c.Stages[stage].Targets < 0 || c.Stages[stage].Rounds < 0 {
+				fail(field, "targets and rounds must not be negative")
+				continue
+			}
+		}
+		if c.StageRounds(stage) < c.StageTargets(stage) {
+			fail(field, "%d rounds are fewer than the %d targets", c.StageRounds(stage), c.StageTargets(stage))
+		}
+	}
+	if !slices.Contains(Formats, c.RaceFormat()) {
+		fail("format", "unknown race format %q, expected one of %s", c.Format, strings.Join(Formats, ", "))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkLayout makes sure a time layout writes and reads back hours, minutes and seconds.
+func checkLayout(layout string) error {
+	reference := time.Date(0, time.January, 1, 13, 14, 15, 0, time.UTC)
+	parsed, err := time.Parse(layout, reference.Format(layout))
+	if err != nil || !parsed.Equal(reference) {
+		return fmt.Errorf("layout %q must contain hours (15), minutes (04) and seconds (05)", layout)
+	}
+	return nil
+}
+
+// secondsFormat reads clock times with any fractional seconds, or none.
+const secondsFormat = "15:04:05"
+
+// StartTime returns the configured race start as a clock time in the venue's zone.
+// Fractional seconds may be left out.
+func (c Configuration) StartTime() (time.Time, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start, err := time.ParseInLocation(c.Layout(), c.Start, loc)
+	if err != nil {
+		if start, secErr := time.ParseInLocation(secondsFormat, c.Start, loc); secErr == nil {
+			return start, nil
+		}
+	}
+	return start, err
+}
+
+// StartDeltaDuration returns the length of the start window after each planned start.
+func (c Configuration) StartDeltaDuration() (time.Duration, error) {
+	return ParseDuration(c.StartDelta)
+}
+
+// MissPenaltyDuration returns the time added per missed target, DefaultMissPenalty if none is configured.
+func (c Configuration) MissPenaltyDuration() (time.Duration, error) {
+	if c.MissPenalty == "" {
+		return DefaultMissPenalty, nil
+	}
+	return ParseDuration(c.MissPenalty)
+}
+
+// SkippedLoopPenaltyDuration returns the time added per penalty loop not skied,
+// DefaultSkippedLoopPenalty if none is configured.
+func (c Configuration) SkippedLoopPenaltyDuration() (time.Duration, error) {
+	if c.SkippedLoopPenalty == "" {
+		return DefaultSkippedLoopPenalty, nil
+	}
+	return ParseDuration(c.SkippedLoopPenalty)
+}
+
+// ParseDuration parses a duration written as HH:MM:SS with optional fractional seconds
+// (HH:MM:SS.mmm), as used for startDelta, reorderWindow, missPenalty, handicapCap and skippedLoopPenalty.
+func ParseDuration(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration %q: expected HH:MM:SS", s)
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 {
+		return 0, fmt.Errorf("invalid hours in duration %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minutes in duration %q", s)
+	}
+	sec, err := time.ParseDuration(parts[2] + "s")
+	if err != nil || sec < 0 || sec >= time.Minute || strings.HasPrefix(parts[2], "+") {
+		return 0, fmt.Errorf("invalid seconds in duration %q", s)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + sec, nil
+}
+
+// FormatDuration writes a duration as HH:MM:SS.mmm, the form ParseDuration reads.
+func FormatDuration(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Configuration {
+	return Configuration{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:01:30",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Errorf("Expected valid configuration, got: %v", err)
+	}
+
+	conf := validConfig()
+	conf.Laps = 0
+	conf.LapLen = -1
+	conf.Start = "ten o'clock"
+	conf.StartDelta = "90s"
+	conf.ReorderWindow = "01:30:00"
+	conf.TimeZone = "Mars/Olympus"
+	conf.TimePrecision = 12
+	conf.Format = "relay race"
+	conf.MissPenalty = "1m"
+	conf.HandicapCap = "00:90:00"
+	conf.PriorResults = "sprintResults"
+	conf.Teams = []Team{{ID: 1, Members: []int{1, 2}}}
+	conf.Rounds = 3
+	conf.Stages = []Stage{{Targets: -1}}
+	conf.SkippedLoops = "warn"
+	conf.SkippedLoopPenalty = "2m"
+
+	err := conf.Validate()
+	if err == nil {
+		t.Fatal("Expected validation errors, got nil")
+	}
+
+	for _, field := range []string{"laps:", "lapLen:", "startDelta:", "reorderWindow:", "timeZone:", "timePrecision:", "format:", "missPenalty:", "handicapCap:", "priorResults:", "teams:", "rounds:", "stages[0]:", "skippedLoops:", "skippedLoopPenalty:"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected an error for %s, got: %v", field, err)
+		}
+	}
+}
+
+func TestValidateStart(t *testing.T) {
+	for _, start := range []string{"09:35:00", "09:35:00.000", "09:35:00.5"} {
+		conf := validConfig()
+		conf.Start = start
+		if err := conf.Validate(); err != nil {
+			t.Errorf("Expected start %q to be valid, got: %v", start, err)
+		}
+	}
+
+	conf := validConfig()
+	conf.Start = "25:00:00"
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "start:") {
+		t.Errorf("Expected an error for start, got: %v", err)
+	}
+
+	conf = validConfig()
+	conf.TimeLayout = "04:05"
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "timeLayout:") {
+		t.Errorf("Expected an error for timeLayout, got: %v", err)
+	}
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configContent := `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2,
+		"start": "10:00:00.000", "startDelta": "00:01:30", "lapLength": 3300}`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "lapLength") {
+		t.Errorf("Expected unknown field error, got: %v", err)
+	}
+}
+
+func TestLoadConfigValidates(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configContent := `{"laps": 0, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2,
+		"start": "10:00:00.000", "startDelta": "1:30"}`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "laps:") || !strings.Contains(err.Error(), "startDelta:") {
+		t.Errorf("Expected laps and startDelta errors, got: %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"00:01:30", 90 * time.Second, false},
+		{"00:00:00.500", 500 * time.Millisecond, false},
+		{"01:05:30.000", time.Hour + 5*time.Minute + 30*time.Second, false},
+		{"01:30", 0, true},
+		{"00:61:00", 0, true},
+		{"00:00:xx", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			d, err := ParseDuration(test.input)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s, got %v", test.input, d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDuration(%s) failed: %v", test.input, err)
+			}
+			if d != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, d)
+			}
+		})
+	}
+}
+
+func TestValidateTeams(t *testing.T) {
+	conf := validConfig()
+	conf.Format = FormatRelay
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "teams: are required") {
+		t.Errorf("Expected teams to be required, got: %v", err)
+	}
+
+	conf.Teams = []Team{{ID: 1, Members: []int{1, 2}}, {ID: 2, Members: []int{3, 1}}, {ID: 2}}
+	err := conf.Validate()
+	for _, problem := range []string{"competitor 1 is in team 1 and team 2", "team 2 is listed twice", "team 2 has no members"} {
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected %q, got: %v", problem, err)
+		}
+	}
+
+	conf.Teams = []Team{{ID: 1, Members: []int{1, 2}}, {ID: 2, Members: []int{3, 4}}}
+	if err := conf.Validate(); err != nil {
+		t.Errorf("Expected valid relay teams, got: %v", err)
+	}
+}
+
+func TestValidateLapLens(t *testing.T) {
+	conf := validConfig()
+	conf.LapLen = 0
+	conf.LapLens = []int{3300, 2500}
+	if err := conf.Validate(); err != nil {
+		t.Errorf("Expected lapLens to replace lapLen, got: %v", err)
+	}
+
+	conf.LapLens = []int{3300, 0, 2500}
+	err := conf.Validate()
+	for _, problem := range []string{"lapLens: has 3 lengths for 2 laps", "lapLens: lap 2 must have a positive length"} {
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected %q, got: %v", problem, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reasons a line can be rejected by ParseEvent. Errors returned by ParseEvent wrap one of them.
+var (
+	ErrInvalidFormat       = errors.New("invalid event format")
+	ErrInvalidTime         = errors.New("invalid time format")
+	ErrUnknownAction       = errors.New("unknown action ID")
+	ErrInvalidCompetitorID = errors.New("invalid competitor ID")
+	ErrInvalidParams       = errors.New("invalid event parameters")
+)
+
+var reasons = []error{ErrInvalidFormat, ErrInvalidTime, ErrUnknownAction, ErrInvalidCompetitorID, ErrInvalidParams}
+
+// ParseError describes an events file line that could not be parsed.
+type ParseError struct {
+	Line int
+	Raw  string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: error parsing event '%s': %v", e.Line, e.Raw, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reason returns the Err* value the line was rejected for, or Err itself if it is none of them.
+func (e *ParseError) Reason() error {
+	for _, reason := range reasons {
+		if errors.Is(e.Err, reason) {
+			return reason
+		}
+	}
+	return e.Err
+}
+
+// Summary formats rejected lines for officials, one per line, with a total per reason.
+// It returns an empty string if nothing was rejected.
+func Summary(errs []*ParseError) string {
+	if len(errs) == 0 {
+		return ""
+	}
+
+	counts := make(map[error]int)
+	for _, e := range errs {
+		counts[e.Reason()]++
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Rejected %d event line(s):\n", len(errs)))
+	for _, e := range errs {
+		summary.WriteString(fmt.Sprintf("  line %d: %v: %s\n", e.Line, e.Err, e.Raw))
+	}
+	for _, reason := range reasons {
+		if counts[reason] > 0 {
+			summary.WriteString(fmt.Sprintf("  %s: %d\n", reason, counts[reason]))
+		}
+	}
+	return summary.String()
+}
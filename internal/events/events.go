@@ -1,49 +1,73 @@
 package events
 
 import (
-	"bufio"
-	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 	"yadro-biathlon/internal/config"
 	"yadro-biathlon/internal/models"
+	"yadro-biathlon/internal/utils"
 )
 
-// ParseTime removes square brackets and parses a time string using the configured format.
+// Codec converts between events and event file lines using a clock's time layout,
+// precision and zone. The package-level functions use DefaultCodec.
+type Codec struct {
+	Clock utils.Clock
+}
+
+// DefaultCodec reads and writes "[hh:mm:ss.mmm]" times in UTC.
+var DefaultCodec = Codec{Clock: utils.DefaultClock}
+
+// NewCodec returns a Codec for the time settings of conf.
+func NewCodec(conf config.Configuration) (Codec, error) {
+	clock, err := utils.NewClock(conf)
+	return Codec{Clock: clock}, err
+}
+
+// ParseTime removes square brackets and parses a time string using the default format.
+// Fractional seconds of another precision (e.g. "10:00:01.5") are accepted as well.
 func ParseTime(timeString string) (time.Time, error) {
-	cleanTimeString := strings.Trim(timeString, "[]")
-	return time.Parse(config.TimeFormat, cleanTimeString)
+	return DefaultCodec.ParseTime(timeString)
 }
 
-// ParseEvent converts a log line into a models.Event, validating action codes and parameters.
+// ParseEvent converts a log line into a models.Event using DefaultCodec.
 func ParseEvent(line string) (models.Event, error) {
+	return DefaultCodec.ParseEvent(line)
+}
+
+// ParseTime removes square brackets and parses a time string using the codec's clock.
+func (c Codec) ParseTime(timeString string) (time.Time, error) {
+	return c.Clock.Parse(timeString)
+}
+
+// ParseEvent converts a log line into a models.Event, validating action codes and parameters.
+// The event's TimeString is the parsed time rewritten with the codec's precision.
+func (c Codec) ParseEvent(line string) (models.Event, error) {
 	event := models.Event{}
 	timeEndIndex := strings.Index(line, "]")
 	if timeEndIndex == -1 {
-		return models.Event{}, errors.New("invalid event")
+		return models.Event{}, fmt.Errorf("%w: missing time", ErrInvalidFormat)
 	}
 
-	timeString := line[:timeEndIndex+1]
-	event.TimeString = timeString
-
-	t, err := ParseTime(timeString)
+	t, err := c.ParseTime(line[:timeEndIndex+1])
 	if err != nil {
-		return event, fmt.Errorf("invalid time format: %v", err)
+		return event, fmt.Errorf("%w: %v", ErrInvalidTime, err)
 	}
 	event.Time = t
+	event.TimeString = c.Clock.FormatTimeString(t)
 
 	remainder := strings.TrimSpace(line[timeEndIndex+1:])
 	parts := strings.Fields(remainder)
 	if len(parts) < 2 {
-		return event, errors.New("invalid event format: not enough parts")
+		return event, fmt.Errorf("%w: not enough parts", ErrInvalidFormat)
 	}
 
 	actionInt, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return event, fmt.Errorf("invalid event ID: %v", err)
+		return event, fmt.Errorf("%w: %v", ErrUnknownAction, err)
 	}
 	action := models.Action(actionInt)
 
@@ -59,16 +83,21 @@ func ParseEvent(line string) (models.Event, error) {
 		models.ActionOnPenaltyLaps,
 		models.ActionLeftPenaltyLaps,
 		models.ActionFinishedLap,
-		models.ActionCannotContinue:
+		models.ActionCannotContinue,
+		models.ActionSpareRound,
+		models.ActionExchange,
+		models.ActionPenaltyLoop,
+		models.ActionDisqualified,
+		models.ActionFinished:
 		// всё ок
 	default:
-		return event, fmt.Errorf("unknown action ID: %d", actionInt)
+		return event, fmt.Errorf("%w: %d", ErrUnknownAction, actionInt)
 	}
 	event.Action = action
 
 	competitorID, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return event, fmt.Errorf("invalid competitor ID: %v", err)
+		return event, fmt.Errorf("%w: %v", ErrInvalidCompetitorID, err)
 	}
 	event.CompetitorID = competitorID
 
@@ -76,10 +105,16 @@ func ParseEvent(line string) (models.Event, error) {
 		event.ExtraParams = strings.Join(parts[2:], " ")
 	}
 
+	event.Payload, err = c.parsePayload(action, event.ExtraParams)
+	if err != nil {
+		return event, err
+	}
+
 	return event, nil
 }
 
 // LoadEvents opens a file, reads non-empty lines, and parses them into an event slice.
+// The file may use either the text or the JSON Lines format.
 func LoadEvents(filename string) ([]models.Event, error) {
 	var events []models.Event
 
@@ -89,23 +124,15 @@ func LoadEvents(filename string) ([]models.Event, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) == 0 {
-			continue
+	reader := NewReader(file)
+	for {
+		event, err := reader.Read()
+		if err == io.EOF {
+			return events, nil
 		}
-
-		event, err := ParseEvent(line)
 		if err != nil {
-			return events, fmt.Errorf("error parsing event '%s': %v", line, err)
+			return events, err
 		}
 		events = append(events, event)
 	}
-
-	if err := scanner.Err(); err != nil {
-		return events, err
-	}
-
-	return events, nil
 }
@@ -1,7 +1,12 @@
 package events
 
 import (
+	"context"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 	"yadro-biathlon/internal/config"
 	"yadro-biathlon/internal/models"
 )
@@ -47,6 +52,8 @@ func TestParseEvent(t *testing.T) {
 		{"[09:58:00.000] 2 3 10:03:00.000", 2, 3, "10:03:00.000"},
 		{"[10:10:22.273] 5 2 1", 5, 2, "1"},
 		{"[10:26:38.368] 6 4 1", 6, 4, "1"},
+		{"[09:31:30.001] 32 2", 32, 2, ""},
+		{"[10:25:26.047] 33 1", 33, 1, ""},
 	}
 
 	for _, test := range tests {
@@ -68,3 +75,365 @@ func TestParseEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestReader(t *testing.T) {
+	input := "[09:31:49.285] 1 3\n\n[09:55:00.000] 2 1 10:00:00.000\n"
+	reader := NewReader(strings.NewReader(input))
+
+	first, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if first.Action != models.ActionRegistered || first.CompetitorID != 3 {
+		t.Errorf("Expected registration of competitor 3, got %+v", first)
+	}
+
+	second, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if second.ExtraParams != "10:00:00.000" {
+		t.Errorf("Expected ExtraParams=10:00:00.000, got %s", second.ExtraParams)
+	}
+	if reader.Line() != 3 {
+		t.Errorf("Expected line 3, got %d", reader.Line())
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader := NewReader(Follow(ctx, pr, time.Millisecond))
+	go func() {
+		pw.Write([]byte("[09:31:49.285] 1 3\n"))
+		pw.Close()
+	}()
+
+	event, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if event.CompetitorID != 3 {
+		t.Errorf("Expected competitor 3, got %d", event.CompetitorID)
+	}
+
+	cancel()
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected io.EOF after cancel, got %v", err)
+	}
+}
+
+func TestReaderLenient(t *testing.T) {
+	input := strings.Join([]string{
+		"[09:31:49.285] 1 3",
+		"[9:61:00] 1 4",
+		"[09:32:17.531] 99 2",
+		"[09:32:18.000] 1 x",
+		"garbage",
+		"[09:37:47.892] 1 5",
+	}, "\n")
+	reader := NewReader(strings.NewReader(input))
+	reader.Lenient = true
+
+	var ids []int
+	for {
+		event, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Lenient Read failed: %v", err)
+		}
+		ids = append(ids, event.CompetitorID)
+	}
+
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 5 {
+		t.Errorf("Expected competitors [3 5], got %v", ids)
+	}
+
+	expected := []struct {
+		line   int
+		reason error
+	}{
+		{2, ErrInvalidTime},
+		{3, ErrUnknownAction},
+		{4, ErrInvalidCompetitorID},
+		{5, ErrInvalidFormat},
+	}
+	rejected := reader.Rejected()
+	if len(rejected) != len(expected) {
+		t.Fatalf("Expected %d rejected lines, got %d", len(expected), len(rejected))
+	}
+	for i, e := range expected {
+		if rejected[i].Line != e.line || rejected[i].Reason() != e.reason {
+			t.Errorf("Expected line %d rejected for %v, got line %d for %v", e.line, e.reason, rejected[i].Line, rejected[i].Reason())
+		}
+	}
+
+	summary := Summary(rejected)
+	if !strings.Contains(summary, "Rejected 4 event line(s)") || !strings.Contains(summary, "line 3: unknown action ID: 99") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+}
+
+func TestReaderStrict(t *testing.T) {
+	reader := NewReader(strings.NewReader("[09:31:49.285] 1 3\n[09:32:17.531] 99 2\n"))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	_, err := reader.Read()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %v", err)
+	}
+	if parseErr.Line != 2 || !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("Expected unknown action on line 2, got %v", err)
+	}
+}
+
+func TestParseJSONEvent(t *testing.T) {
+	tests := []struct {
+		text string
+		json string
+	}{
+		{"[09:38:28.673] 1 1", `{"time": "09:38:28.673", "action": 1, "competitor": 1}`},
+		{"[09:58:00.000] 2 3 10:03:00.000", `{"time": "09:58:00.000", "action": 2, "competitor": 3, "params": "10:03:00.000"}`},
+		{"[10:10:22.273] 5 2 1", `{"time": "10:10:22.273", "action": 5, "competitor": 2, "params": 1}`},
+		{"[10:26:38.368] 6 4 1", `{"time": "10:26:38.368", "action": "6", "competitor": "4", "params": ["1"]}`},
+		{"[09:59:05.321] 11 1 Lost in the forest", `{"time": "09:59:05.321", "action": 11, "competitor": 1, "params": "Lost in the forest"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			expected, err := ParseEvent(test.text)
+			if err != nil {
+				t.Fatalf("ParseEvent(%s) failed: %v", test.text, err)
+			}
+			parsed, err := ParseJSONEvent(test.json)
+			if err != nil {
+				t.Fatalf("ParseJSONEvent(%s) failed: %v", test.json, err)
+			}
+			if parsed != expected {
+				t.Errorf("Expected %+v, got %+v", expected, parsed)
+			}
+		})
+	}
+}
+
+func TestParseJSONEventErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason error
+	}{
+		{`{"time": "09:38:28.673", "action": 1`, ErrInvalidFormat},
+		{`{"action": 1, "competitor": 1}`, ErrInvalidFormat},
+		{`{"time": "9h", "action": 1, "competitor": 1}`, ErrInvalidTime},
+		{`{"time": "09:38:28.673", "action": 42, "competitor": 1}`, ErrUnknownAction},
+		{`{"time": "09:38:28.673", "action": 1, "competitor": "one"}`, ErrInvalidCompetitorID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseJSONEvent(test.input)
+			if !errors.Is(err, test.reason) {
+				t.Errorf("Expected %v, got %v", test.reason, err)
+			}
+		})
+	}
+}
+
+func TestReaderDetectsJSON(t *testing.T) {
+	input := "\n" + `{"time": "09:31:49.285", "action": 1, "competitor": 3}` + "\n"
+	reader := NewReader(strings.NewReader(input))
+
+	event, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if reader.Format != FormatJSON {
+		t.Errorf("Expected JSON format to be detected, got %v", reader.Format)
+	}
+	if event.CompetitorID != 3 || event.TimeString != "[09:31:49.285]" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	forced := NewReader(strings.NewReader(input))
+	forced.Format = FormatText
+	if _, err := forced.Read(); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Expected text format to reject JSON, got %v", err)
+	}
+}
+
+func TestFormatEventRoundTrip(t *testing.T) {
+	events, err := LoadEvents("../config/events")
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+
+	for _, event := range events {
+		line := FormatEvent(event)
+		parsed, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%s) failed: %v", line, err)
+		}
+		if parsed != event {
+			t.Errorf("Round trip of %s: expected %+v, got %+v", line, event, parsed)
+		}
+	}
+}
+
+func TestFormatEventNormalizes(t *testing.T) {
+	event, err := ParseEvent("[09:59:05.3]   11 1   Lost   in the forest")
+	if err != nil {
+		t.Fatalf("ParseEvent failed: %v", err)
+	}
+
+	expected := "[09:59:05.300] 11 1 Lost in the forest"
+	if line := FormatEvent(event); line != expected {
+		t.Errorf("Expected %s, got %s", expected, line)
+	}
+
+	event, err = ParseEvent("[10:00:00.000] 2 1 10:30:00.5")
+	if err != nil {
+		t.Fatalf("ParseEvent failed: %v", err)
+	}
+	expected = "[10:00:00.000] 2 1 10:30:00.500"
+	if line := FormatEvent(event); line != expected {
+		t.Errorf("Expected %s, got %s", expected, line)
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+	var events []models.Event
+	for _, line := range []string{
+		"[00:00:02.000] 4 1",
+		"[23:59:58.000] 3 1",
+		"[23:59:58.000] 3 2",
+		"[23:59:30.000] 1 1",
+	} {
+		event, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%s) failed: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	PlaceEvents(events, config.NoDate)
+
+	var out strings.Builder
+	if err := WriteEvents(&out, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	expected := "[23:59:30.000] 1 1\n[23:59:58.000] 3 1\n[23:59:58.000] 3 2\n[00:00:02.000] 4 1\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestParseEventPayload(t *testing.T) {
+	start, _ := time.Parse(config.TimeFormat, "10:03:00.000")
+	tests := []struct {
+		input    string
+		expected models.Payload
+	}{
+		{"[09:38:28.673] 1 1", nil},
+		{"[09:58:00.000] 2 3 10:03:00.000", models.StartTimePayload{Start: start}},
+		{"[10:10:22.273] 5 2 1", models.FiringRangePayload{Range: 1}},
+		{"[10:26:38.368] 6 4 5", models.TargetPayload{Target: 5}},
+		{"[09:59:05.321] 11 1 Lost in the forest", models.ReasonPayload{Reason: "Lost in the forest"}},
+		{"[10:12:00.000] 12 1", nil},
+		{"[10:30:00.000] 13 1 2", models.ExchangePayload{Next: 2}},
+		{"[10:31:00.000] 14 1", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			event, err := ParseEvent(test.input)
+			if err != nil {
+				t.Fatalf("ParseEvent(%s) failed: %v", test.input, err)
+			}
+			if event.Payload != test.expected {
+				t.Errorf("Expected payload %+v, got %+v", test.expected, event.Payload)
+			}
+		})
+	}
+}
+
+func TestParseEventInvalidPayload(t *testing.T) {
+	for _, input := range []string{
+		"[09:58:00.000] 2 3 soon",
+		"[09:58:00.000] 2 3",
+		"[10:10:22.273] 5 2 0",
+		"[10:10:22.273] 5 2",
+		"[10:26:38.368] 6 4 x",
+		"[10:30:00.000] 13 1",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseEvent(input); !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected %v, got %v", ErrInvalidParams, err)
+			}
+		})
+	}
+}
+
+func TestReaderValidatesAgainstConfig(t *testing.T) {
+	reader := NewReader(strings.NewReader("[10:10:22.273] 5 2 2\n[10:12:22.273] 5 2 3\n"))
+	reader.Config = &config.Configuration{FiringLines: 2}
+
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	_, err := reader.Read()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected invalid parameters on line 2, got %v", err)
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	event, err := ParseEvent("[10:26:38.368] 6 4 9")
+	if err != nil {
+		t.Fatalf("ParseEvent failed: %v", err)
+	}
+
+	if err := Validate(event, config.Configuration{}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected %v with the default 5 targets, got %v", ErrInvalidParams, err)
+	}
+	if err := Validate(event, config.Configuration{Stages: []config.Stage{{}, {Targets: 10}}}); err != nil {
+		t.Errorf("Expected target 9 to be valid with a 10 target stage, got %v", err)
+	}
+}
+
+func TestCodecWithConfiguredClock(t *testing.T) {
+	codec, err := NewCodec(config.Configuration{TimeLayout: "15:04:05,000000", TimePrecision: 6})
+	if err != nil {
+		t.Fatalf("NewCodec failed: %v", err)
+	}
+
+	line := "[09:55:00,000250] 2 1 10:00:00,500000"
+	event, err := codec.ParseEvent(line)
+	if err != nil {
+		t.Fatalf("ParseEvent(%s) failed: %v", line, err)
+	}
+	if event.Time.Nanosecond() != 250_000 {
+		t.Errorf("Expected microseconds to be kept, got %d ns", event.Time.Nanosecond())
+	}
+
+	start := event.Payload.(models.StartTimePayload).Start
+	if start.Format(config.TimeFormat) != "10:00:00.500" {
+		t.Errorf("Expected start time 10:00:00.500, got %s", start.Format(config.TimeFormat))
+	}
+
+	if formatted := codec.FormatEvent(event); formatted != line {
+		t.Errorf("Expected %s, got %s", line, formatted)
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// followReader waits for more data at the end of input instead of returning io.EOF.
+type followReader struct {
+	ctx  context.Context
+	r    io.Reader
+	poll time.Duration
+}
+
+// Follow wraps r so that reaching the end of input polls for new data every poll
+// interval, like `tail -f`. It is meant for event files the timing system is still
+// writing to. The returned reader reports io.EOF only after ctx is done.
+func Follow(ctx context.Context, r io.Reader, poll time.Duration) io.Reader {
+	return &followReader{ctx: ctx, r: r, poll: poll}
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(f.poll):
+		}
+	}
+}
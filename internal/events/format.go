@@ -0,0 +1,36 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format identifies how events are written in an events file.
+type Format int
+
+const (
+	FormatAuto Format = iota // detect from the first non-empty line
+	FormatText               // [hh:mm:ss.mmm] action competitor extra
+	FormatJSON               // one JSON object per line
+)
+
+// ParseFormat converts a format name ("auto", "text" or "json") into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return FormatAuto, nil
+	case "text":
+		return FormatText, nil
+	case "json", "jsonl":
+		return FormatJSON, nil
+	}
+	return FormatAuto, fmt.Errorf("unknown events format: %s", name)
+}
+
+// DetectFormat guesses the format of an events file from one of its non-empty lines.
+func DetectFormat(line string) Format {
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return FormatJSON
+	}
+	return FormatText
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"yadro-biathlon/internal/models"
+)
+
+// jsonEvent is one line of the JSON Lines event format, e.g.
+// {"time": "09:55:00.000", "action": 2, "competitor": 1, "params": "10:00:00.000"}.
+// Params may also be a number or an array of values that are joined with spaces.
+type jsonEvent struct {
+	Time       string          `json:"time"`
+	Action     json.RawMessage `json:"action"`
+	Competitor json.RawMessage `json:"competitor"`
+	Params     json.RawMessage `json:"params"`
+}
+
+// ParseJSONEvent converts a JSON Lines record into a models.Event using DefaultCodec.
+func ParseJSONEvent(line string) (models.Event, error) {
+	return DefaultCodec.ParseJSONEvent(line)
+}
+
+// ParseJSONEvent converts a JSON Lines record into a models.Event.
+// It applies the same validation as ParseEvent and yields identical events for the same data.
+func (c Codec) ParseJSONEvent(line string) (models.Event, error) {
+	var record jsonEvent
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return models.Event{}, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	if record.Time == "" || len(record.Action) == 0 || len(record.Competitor) == 0 {
+		return models.Event{}, fmt.Errorf("%w: time, action and competitor are required", ErrInvalidFormat)
+	}
+
+	params, err := jsonParams(record.Params)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("%w: params: %v", ErrInvalidFormat, err)
+	}
+
+	parts := []string{"[" + strings.Trim(record.Time, "[]") + "]", jsonScalar(record.Action), jsonScalar(record.Competitor)}
+	if params != "" {
+		parts = append(parts, params)
+	}
+	return c.ParseEvent(strings.Join(parts, " "))
+}
+
+// jsonScalar returns the text of a JSON number or string without quotes.
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func jsonParams(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, jsonScalar(item))
+		}
+		return strings.Join(values, " "), nil
+	}
+
+	if raw[0] == '{' {
+		return "", fmt.Errorf("expected a string, number or array")
+	}
+	return jsonScalar(raw), nil
+}
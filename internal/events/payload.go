@@ -0,0 +1,86 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+// parsePayload converts the extra parameters of an event into the typed payload of its action.
+// Actions without parameters get a nil payload; extra text on them is kept only in ExtraParams.
+func (c Codec) parsePayload(action models.Action, extra string) (models.Payload, error) {
+	switch action {
+	case models.ActionStartTimeSet:
+		start, err := c.ParseTime(extra)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start time %q: %v", ErrInvalidParams, extra, err)
+		}
+		return models.StartTimePayload{Start: start}, nil
+	case models.ActionOnFiringRange:
+		firingRange, err := parsePositive(extra)
+		if err != nil {
+			return nil, fmt.Errorf("%w: firing range %v", ErrInvalidParams, err)
+		}
+		return models.FiringRangePayload{Range: firingRange}, nil
+	case models.ActionHit:
+		target, err := parsePositive(extra)
+		if err != nil {
+			return nil, fmt.Errorf("%w: target %v", ErrInvalidParams, err)
+		}
+		return models.TargetPayload{Target: target}, nil
+	case models.ActionCannotContinue:
+		return models.ReasonPayload{Reason: extra}, nil
+	case models.ActionExchange:
+		next, err := parsePositive(extra)
+		if err != nil {
+			return nil, fmt.Errorf("%w: next competitor %v", ErrInvalidParams, err)
+		}
+		return models.ExchangePayload{Next: next}, nil
+	}
+	return nil, nil
+}
+
+// formatPayload writes the extra parameters of an event from its typed payload, with the start
+// time in the codec's layout and precision. Events without a payload keep their ExtraParams.
+func (c Codec) formatPayload(event models.Event) string {
+	switch payload := event.Payload.(type) {
+	case models.StartTimePayload:
+		return c.Clock.Format(payload.Start)
+	case models.FiringRangePayload:
+		return strconv.Itoa(payload.Range)
+	case models.TargetPayload:
+		return strconv.Itoa(payload.Target)
+	case models.ReasonPayload:
+		return strings.Join(strings.Fields(payload.Reason), " ")
+	case models.ExchangePayload:
+		return strconv.Itoa(payload.Next)
+	}
+	return strings.Join(strings.Fields(event.ExtraParams), " ")
+}
+
+func parsePositive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q: expected a positive number", s)
+	}
+	return n, nil
+}
+
+// Validate checks an event's payload against the race configuration,
+// e.g. that a firing range number does not exceed FiringLines or a target the targets of a stage.
+func Validate(event models.Event, conf config.Configuration) error {
+	switch payload := event.Payload.(type) {
+	case models.FiringRangePayload:
+		if conf.FiringLines > 0 && payload.Range > conf.FiringLines {
+			return fmt.Errorf("%w: firing range %d, the race has only %d firing lines",
+				ErrInvalidParams, payload.Range, conf.FiringLines)
+		}
+	case models.TargetPayload:
+		if payload.Target > conf.MaxTargets() {
+			return fmt.Errorf("%w: target %d, there are only %d targets", ErrInvalidParams, payload.Target, conf.MaxTargets())
+		}
+	}
+	return nil
+}
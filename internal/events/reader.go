@@ -0,0 +1,106 @@
+package events
+
+import (
+	"bufio"
+	"io"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+// Reader parses events incrementally from an io.Reader, one line at a time,
+// so a race can be processed while the timing system is still writing it.
+type Reader struct {
+	// Lenient makes Read skip lines that cannot be parsed instead of failing.
+	// Skipped lines are available from Rejected.
+	Lenient bool
+
+	// Format selects the line format. FormatAuto detects it from the first non-empty line.
+	Format Format
+
+	// Config, if set, provides the time layout and zone of event times
+	// and is used to validate event parameters against the race, see Validate.
+	Config *config.Configuration
+
+	scanner  *bufio.Scanner
+	line     int
+	rejected []*ParseError
+	parser   *Codec
+}
+
+// NewReader returns a Reader that parses events from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next event, skipping empty lines.
+// A line that cannot be parsed is returned as a *ParseError unless the reader is lenient.
+// It returns io.EOF once the underlying reader is exhausted.
+func (r *Reader) Read() (models.Event, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+
+		if r.Format == FormatAuto {
+			r.Format = DetectFormat(line)
+		}
+
+		codec, err := r.codec()
+		if err != nil {
+			return models.Event{}, err
+		}
+		parse := codec.ParseEvent
+		if r.Format == FormatJSON {
+			parse = codec.ParseJSONEvent
+		}
+
+		event, err := parse(line)
+		if err == nil && r.Config != nil {
+			err = Validate(event, *r.Config)
+		}
+		if err != nil {
+			parseErr := &ParseError{Line: r.line, Raw: line, Err: err}
+			if r.Lenient {
+				r.rejected = append(r.rejected, parseErr)
+				continue
+			}
+			return event, parseErr
+		}
+		return event, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return models.Event{}, err
+	}
+	return models.Event{}, io.EOF
+}
+
+// Line returns the number of the last line read, starting from 1.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Rejected returns the lines skipped so far in lenient mode.
+func (r *Reader) Rejected() []*ParseError {
+	return r.rejected
+}
+
+// codec returns the codec for the reader's Config, or DefaultCodec without one.
+// It is created on the first call.
+func (r *Reader) codec() (Codec, error) {
+	if r.parser != nil {
+		return *r.parser, nil
+	}
+
+	codec := DefaultCodec
+	if r.Config != nil {
+		var err error
+		if codec, err = NewCodec(*r.Config); err != nil {
+			return codec, err
+		}
+	}
+	r.parser = &codec
+	return codec, nil
+}
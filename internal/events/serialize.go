@@ -0,0 +1,70 @@
+package events
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"yadro-biathlon/internal/models"
+	"yadro-biathlon/internal/utils"
+)
+
+// FormatEvent converts an event back into the line format read by ParseEvent using DefaultCodec.
+func FormatEvent(event models.Event) string {
+	return DefaultCodec.FormatEvent(event)
+}
+
+// FormatEvent converts an event back into the line format read by ParseEvent:
+// "[hh:mm:ss.mmm] action competitor extra". The time and a drawn start time are written
+// with the codec's precision, so the date is dropped; c.ParseEvent(c.FormatEvent(e)) returns e
+// for every event c.ParseEvent can produce when the precision covers the layout's digits.
+func (c Codec) FormatEvent(event models.Event) string {
+	var line strings.Builder
+	line.WriteString(c.Clock.FormatTimeString(event.Time) + " ")
+	line.WriteString(strconv.Itoa(int(event.Action)))
+	line.WriteString(" ")
+	line.WriteString(strconv.Itoa(event.CompetitorID))
+	if extra := c.formatPayload(event); extra != "" {
+		line.WriteString(" " + extra)
+	}
+	return line.String()
+}
+
+// SortEvents orders events by time, keeping the original order of events with equal times.
+// Clock-only times of a race that passes midnight must be placed on a timeline first, see PlaceEvents.
+func SortEvents(events []models.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+}
+
+// PlaceEvents puts the clock times of events, in the order they were recorded,
+// on the calendar days of a race starting on date, so that times after midnight sort last.
+func PlaceEvents(events []models.Event, date time.Time) {
+	timeline := utils.NewTimeline(date)
+	for i := range events {
+		events[i].Time = timeline.Place(events[i].Time)
+	}
+}
+
+// WriteEvents writes events in canonical form using DefaultCodec.
+func WriteEvents(w io.Writer, events []models.Event) error {
+	return DefaultCodec.WriteEvents(w, events)
+}
+
+// WriteEvents writes events in canonical form: sorted by time, one FormatEvent line each.
+func (c Codec) WriteEvents(w io.Writer, events []models.Event) error {
+	sorted := make([]models.Event, len(events))
+	copy(sorted, events)
+	SortEvents(sorted)
+
+	writer := bufio.NewWriter(w)
+	for _, event := range sorted {
+		if _, err := writer.WriteString(c.FormatEvent(event) + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -3,6 +3,7 @@ package messages
 const (
 	Registered         = "%s The competitor(%d) registered"
 	StartTimeSet       = "%s The start time for the competitor(%d) was set by a draw to %s"
+	StartTimeAssigned  = "%s The drawn start time %s of the competitor(%d) is ignored, the race format starts them at %s"
 	OnStartLine        = "%s The competitor(%d) is on the start line"
 	Started            = "%s The competitor(%d) has started"
 	OnFiringRange      = "%s The competitor(%d) is on the firing range(%s)"
@@ -10,8 +11,17 @@ const (
 	LeftFiringRange    = "%s The competitor(%d) left the firing range"
 	EnteredPenaltyLaps = "%s The competitor(%d) entered the penalty laps"
 	LeftPenaltyLaps    = "%s The competitor(%d) left the penalty laps"
+	PenaltyLoop        = "%s The competitor(%d) passed penalty loop %d"
 	MainLapEnded       = "%s The competitor(%d) ended the main lap"
 	CannotContinue     = "%s The competitor(%d) can`t continue: %s"
+	SpareRound         = "%s The competitor(%d) loaded a spare round"
+	Exchange           = "%s The competitor(%d) handed over to the competitor(%d)"
+	IrregularExchange  = "%s The competitor(%d) has an irregular exchange: %s"
 	Disqualified       = "%s The competitor(%d) is disqualified"
 	Finished           = "%s The competitor(%d) has finished"
+	IrregularShooting  = "%s The competitor(%d) has irregular shooting: %s"
+	SkippedLoops       = "%s The competitor(%d) skipped penalty loops: %s"
+	EventOutOfOrder    = "%s The event(%d) of competitor(%d) is %s older than the latest event"
+	EventTooLate       = "%s The event(%d) of competitor(%d) was rejected: %v"
+	Anomaly            = "%s The event(%d) of competitor(%d) is not allowed in state %s, expected %s"
 )
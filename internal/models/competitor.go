@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type CompetitorStatus int
 
@@ -16,8 +19,31 @@ const (
 	Finished
 	NotFinished
 	NotStarted
+	Disqualified // finished, but their result was taken away
 )
 
+var statusNames = [...]string{
+	Registered:      "Registered",
+	OnStartLine:     "OnStartLine",
+	Started:         "Started",
+	OnFiringRange:   "OnFiringRange",
+	LeftFiringRange: "LeftFiringRange",
+	OnPenaltyLaps:   "OnPenaltyLaps",
+	LeftPenaltyLaps: "LeftPenaltyLaps",
+	FinishedLap:     "FinishedLap",
+	Finished:        "Finished",
+	NotFinished:     "NotFinished",
+	NotStarted:      "NotStarted",
+	Disqualified:    "Disqualified",
+}
+
+func (s CompetitorStatus) String() string {
+	if s >= 0 && int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return "CompetitorStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
 type LapResult struct {
 	Time  time.Duration
 	Speed float64
@@ -28,6 +54,65 @@ type PenaltyResult struct {
 	Speed float64
 }
 
+// PenaltyVisit records one pass through the penalty loops.
+type PenaltyVisit struct {
+	Stage  int // shooting stage it follows, numbered from 1; 0 if no stage was recorded
+	Misses int // targets left standing at that stage
+	Loops  int // penalty loops owed for them
+	Passed int // loops counted by passing events, 0 if none were reported
+	Time   time.Duration
+	Speed  float64
+}
+
+// Position is the shooting position of a stage.
+type Position int
+
+const (
+	Prone Position = iota
+	Standing
+)
+
+func (p Position) String() string {
+	if p == Standing {
+		return "standing"
+	}
+	return "prone"
+}
+
+// ShootingStage records one visit of a competitor to a firing range.
+type ShootingStage struct {
+	Range      int      // firing range number from the event
+	Lap        int      // lap the competitor was on when arriving
+	Position   Position // position the race format prescribes for the stage
+	Targets    int
+	Rounds     int // rounds available; those beyond Targets are spares loaded by hand
+	Hits       int
+	Spares     int    // spare rounds loaded
+	TargetHits []bool // whether each target, numbered from 1, was hit
+	Arrival    time.Time
+	Departure  time.Time // zero while the competitor is still on the range
+
+	RangeTime    time.Duration // from arrival to departure, set on departure
+	ShootingTime time.Duration // from arrival to the last counted hit, zero without hits
+}
+
+// Misses returns the number of targets left standing.
+func (s ShootingStage) Misses() int {
+	return s.Targets - s.Hits
+}
+
+// Pattern returns the stage's targets in order as x for a hit and . for a miss, e.g. x.xx.
+func (s ShootingStage) Pattern() string {
+	pattern := make([]byte, len(s.TargetHits))
+	for i, hit := range s.TargetHits {
+		pattern[i] = '.'
+		if hit {
+			pattern[i] = 'x'
+		}
+	}
+	return string(pattern)
+}
+
 type Competitor struct {
 	ID               int
 	Status           CompetitorStatus
@@ -35,13 +120,20 @@ type Competitor struct {
 	ActualStart      time.Time
 	CurrentLap       int
 	LapsResult       []LapResult
-	PenaltyResult    PenaltyResult
+	PenaltyResult    PenaltyResult // totals over PenaltyVisits
+	PenaltyVisits    []PenaltyVisit
 	LapStartTime     time.Time
 	PenaltyStartTime time.Time
+	LoopsPassed      int // passing events of the current visit to the penalty loops
 	FullPenaltyTime  time.Duration
 	LastFiringHits   int
 	Hits             int
 	Shots            int
 	TotalTime        time.Duration
+	TimePenalty      time.Duration // added to TotalTime in formats that penalise misses with time
+	FinishTime       time.Time
 	Comment          string
+	Stages           []ShootingStage
+	Flags            []string // irregularities found in the race, shown in the report
+	SkippedLoops     int      // penalty loops owed but not skied, found once the race is over
 }
@@ -16,13 +16,64 @@ const (
 	ActionLeftPenaltyLaps                   // участник покинул штрафные круги
 	ActionFinishedLap                       // участник закончил круг
 	ActionCannotContinue                    // участник не может продолжить
+	ActionSpareRound                        // участник зарядил запасной патрон
+	ActionExchange                          // участник передал эстафету
+	ActionPenaltyLoop                       // участник прошёл штрафной круг
+)
 
+// Outgoing events are generated by the processor rather than the timing system.
+const (
+	ActionDisqualified Action = 32 // участник дисквалифицирован
+	ActionFinished     Action = 33 // участник финишировал
 )
 
+// Outgoing reports whether the action is one the processor generates itself.
+func (a Action) Outgoing() bool {
+	return a == ActionDisqualified || a == ActionFinished
+}
+
 type Event struct {
 	Time         time.Time
 	TimeString   string
 	Action       Action
 	CompetitorID int
 	ExtraParams  string
+	Payload      Payload
 }
+
+// Payload is the typed, validated form of Event.ExtraParams.
+// Its concrete type depends on the action; actions without parameters have none.
+type Payload interface {
+	isPayload()
+}
+
+// StartTimePayload carries the drawn start time of ActionStartTimeSet.
+type StartTimePayload struct {
+	Start time.Time
+}
+
+// FiringRangePayload carries the firing range number of ActionOnFiringRange.
+type FiringRangePayload struct {
+	Range int
+}
+
+// TargetPayload carries the target number of ActionHit.
+type TargetPayload struct {
+	Target int
+}
+
+// ExchangePayload carries the competitor taking over in ActionExchange.
+type ExchangePayload struct {
+	Next int
+}
+
+// ReasonPayload carries the free-text reason of ActionCannotContinue.
+type ReasonPayload struct {
+	Reason string
+}
+
+func (StartTimePayload) isPayload()   {}
+func (FiringRangePayload) isPayload() {}
+func (TargetPayload) isPayload()      {}
+func (ReasonPayload) isPayload()      {}
+func (ExchangePayload) isPayload()    {}
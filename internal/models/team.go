@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Team is a relay team. Its athletes ski the legs one after another, and the team's
+// time runs from the start of the first leg to the finish of the last one.
+type Team struct {
+	ID   int
+	Legs []Leg
+}
+
+// Leg is one athlete's part of a relay.
+type Leg struct {
+	CompetitorID int
+	Start        time.Time // the relay start for the first leg, the exchange for the others
+	Finish       time.Time
+}
+
+// Finished reports whether every leg has finished. A leg handed over before its finish,
+// or whose finish was taken away, leaves the team unfinished.
+func (t *Team) Finished() bool {
+	for _, leg := range t.Legs {
+		if leg.Finish.IsZero() {
+			return false
+		}
+	}
+	return len(t.Legs) > 0
+}
+
+// TotalTime returns the time from the relay start to the finish of the last leg.
+func (t *Team) TotalTime() time.Duration {
+	if !t.Finished() {
+		return 0
+	}
+	return t.Legs[len(t.Legs)-1].Finish.Sub(t.Legs[0].Start)
+}
+
+// Leg returns the index of the competitor's leg, or -1 if they are not in the team.
+func (t *Team) Leg(competitorID int) int {
+	for i, leg := range t.Legs {
+		if leg.CompetitorID == competitorID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,34 @@
+package models
+
+// Transitions declares the competitor states each incoming action may arrive in.
+// An action arriving in any other state is an anomaly. Outgoing actions are not listed:
+// the processor generates them itself.
+var Transitions = map[Action][]CompetitorStatus{
+	ActionRegistered:      {Registered},
+	ActionStartTimeSet:    {Registered, OnStartLine},
+	ActionOnStartLine:     {Registered},
+	ActionStarted:         {Registered, OnStartLine},
+	ActionOnFiringRange:   {Started, LeftFiringRange, LeftPenaltyLaps, FinishedLap},
+	ActionHit:             {OnFiringRange},
+	ActionLeftFiringRange: {OnFiringRange},
+	ActionOnPenaltyLaps:   {LeftFiringRange},
+	ActionLeftPenaltyLaps: {OnPenaltyLaps},
+	ActionFinishedLap:     {Started, LeftFiringRange, LeftPenaltyLaps, FinishedLap},
+	ActionCannotContinue: {
+		Registered, OnStartLine, Started, OnFiringRange, LeftFiringRange,
+		OnPenaltyLaps, LeftPenaltyLaps, FinishedLap,
+	},
+	ActionSpareRound:  {OnFiringRange},
+	ActionExchange:    {Finished},
+	ActionPenaltyLoop: {OnPenaltyLaps},
+}
+
+// Allowed reports whether the action may arrive while a competitor is in the given state.
+func Allowed(action Action, status CompetitorStatus) bool {
+	for _, s := range Transitions[action] {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"yadro-biathlon/internal/messages"
+	"yadro-biathlon/internal/models"
+)
+
+// Anomaly is an event that arrived while its competitor was in a state the
+// transition table does not allow it in, e.g. a hit before reaching the firing range.
+type Anomaly struct {
+	Event    models.Event
+	Status   models.CompetitorStatus
+	Expected []models.CompetitorStatus
+}
+
+func (a *Anomaly) Error() string {
+	return fmt.Sprintf("%s event(%d) of competitor(%d) in state %s, expected %s",
+		a.Event.TimeString, a.Event.Action, a.Event.CompetitorID, a.Status, statusList(a.Expected))
+}
+
+func statusList(statuses []models.CompetitorStatus) string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = status.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+// checkTransition records an anomaly if the event is not allowed in the competitor's state.
+// In strict mode the anomaly stops processing. It reports whether the event may be applied.
+func (ep *EventProcessor) checkTransition(event models.Event, comp *models.Competitor) bool {
+	if models.Allowed(event.Action, comp.Status) {
+		return true
+	}
+
+	anomaly := &Anomaly{Event: event, Status: comp.Status, Expected: models.Transitions[event.Action]}
+	ep.Anomalies = append(ep.Anomalies, anomaly)
+	ep.WriteLog(fmt.Sprintf(messages.Anomaly, event.TimeString, event.Action, comp.ID, comp.Status, statusList(anomaly.Expected)))
+
+	if ep.Strict {
+		ep.err = anomaly
+		return false
+	}
+	return true
+}
+
+// Err returns the anomaly that stopped processing in strict mode, or nil.
+func (ep *EventProcessor) Err() error {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.err == nil {
+		return nil
+	}
+	return ep.err
+}
+
+// anomalyReport returns the anomalies section of the final report, empty if there were none.
+func (ep *EventProcessor) anomalyReport() string {
+	if len(ep.Anomalies) == 0 {
+		return ""
+	}
+
+	var report strings.Builder
+	report.WriteString("\nAnomalies:\n")
+	for _, anomaly := range ep.Anomalies {
+		report.WriteString(anomaly.Error() + "\n")
+	}
+	return report.String()
+}
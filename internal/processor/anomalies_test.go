@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"yadro-biathlon/internal/models"
+)
+
+func anomalousEvents() []models.Event {
+	return []models.Event{
+		createTestEvent(models.ActionRegistered, 1, "09:05:59.867", ""),
+		createTestEvent(models.ActionHit, 1, "09:10:00.000", "1"),
+		createTestEvent(models.ActionFinishedLap, 1, "09:20:00.000", ""),
+		createTestEvent(models.ActionStarted, 2, "09:30:00.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 2, "09:40:00.000", ""),
+	}
+}
+
+func TestAnomalies(t *testing.T) {
+	processor := createTestProcessor()
+
+	var report string
+	output := captureOutput(func() {
+		if err := processor.ProcessEvents(anomalousEvents()); err != nil {
+			t.Errorf("Expected anomalies not to be fatal, got: %v", err)
+		}
+		report = processor.GenerateReport()
+	})
+
+	if len(processor.Anomalies) != 3 {
+		t.Fatalf("Expected 3 anomalies, got %d: %v", len(processor.Anomalies), processor.Anomalies)
+	}
+	if !strings.Contains(output, "[09:10:00.000] The event(6) of competitor(1) is not allowed in state Registered, expected OnFiringRange") {
+		t.Errorf("Expected the hit to be logged as an anomaly, got: %s", output)
+	}
+	if len(processor.Competitors[1].LapsResult) != 1 {
+		t.Errorf("Expected the lap end to be applied anyway, got %d laps", len(processor.Competitors[1].LapsResult))
+	}
+
+	_, anomalies, found := strings.Cut(report, "\nAnomalies:\n")
+	expected := "[09:10:00.000] event(6) of competitor(1) in state Registered, expected OnFiringRange\n" +
+		"[09:20:00.000] event(10) of competitor(1) in state Registered, expected Started or LeftFiringRange or LeftPenaltyLaps or FinishedLap\n" +
+		"[09:40:00.000] event(9) of competitor(2) in state Started, expected OnPenaltyLaps\n"
+	if !found || anomalies != expected {
+		t.Errorf("Expected anomalies section:\n%s\ngot:\n%s", expected, report)
+	}
+}
+
+func TestStrictAnomaly(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Strict = true
+
+	var err error
+	captureOutput(func() {
+		err = processor.ProcessStream(&sliceSource{events: anomalousEvents()}, nil)
+	})
+
+	var anomaly *Anomaly
+	if !errors.As(err, &anomaly) || anomaly.Event.Action != models.ActionHit {
+		t.Fatalf("Expected the hit to stop processing, got: %v", err)
+	}
+	if len(processor.Events) != 1 || processor.Competitors[1].Hits != 0 {
+		t.Errorf("Expected only the registration to be applied, got %d events and %d hits",
+			len(processor.Events), processor.Competitors[1].Hits)
+	}
+	if _, ok := processor.Competitors[2]; ok {
+		t.Error("Expected events after the anomaly to be ignored")
+	}
+}
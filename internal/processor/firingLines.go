@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"fmt"
+	"yadro-biathlon/internal/models"
+)
+
+// checkFiringLines compares a finisher's range visits with the configured firing lines.
+// Stage k is expected at firing range k, every range exactly once, and visits spread
+// over the laps with no more than ceil(FiringLines/Laps) on any one lap.
+// It returns a description of every irregularity found.
+func (ep *EventProcessor) checkFiringLines(comp *models.Competitor) []string {
+	lines := ep.Config.FiringLines
+	if lines <= 0 {
+		return nil
+	}
+
+	var issues []string
+	if len(comp.Stages) != lines {
+		issues = append(issues, fmt.Sprintf("%d firing range visits, expected %d", len(comp.Stages), lines))
+	}
+
+	visits := make(map[int]int)
+	for i, stage := range comp.Stages {
+		visits[stage.Range]++
+		if visits[stage.Range] == 2 {
+			issues = append(issues, fmt.Sprintf("repeated firing range %d", stage.Range))
+		}
+		if i < lines && stage.Range != i+1 && visits[stage.Range] == 1 {
+			issues = append(issues, fmt.Sprintf("stage %d shot at firing range %d", i+1, stage.Range))
+		}
+	}
+	for r := 1; r <= lines; r++ {
+		if visits[r] == 0 {
+			issues = append(issues, fmt.Sprintf("skipped firing range %d", r))
+		}
+	}
+
+	if ep.Config.Laps > 0 {
+		perLap := (lines + ep.Config.Laps - 1) / ep.Config.Laps
+		stagesOnLap := make(map[int]int)
+		for _, stage := range comp.Stages {
+			stagesOnLap[stage.Lap]++
+			if stagesOnLap[stage.Lap] == perLap+1 {
+				issues = append(issues, fmt.Sprintf("more than %d firing range visits on lap %d", perLap, stage.Lap))
+			}
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+	"yadro-biathlon/internal/models"
+)
+
+func TestCheckFiringLines(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.FiringLines = 2
+
+	tests := []struct {
+		name     string
+		stages   []models.ShootingStage
+		expected []string
+	}{
+		{"regular", []models.ShootingStage{{Range: 1, Lap: 1}, {Range: 2, Lap: 2}}, nil},
+		{"skipped", []models.ShootingStage{{Range: 1, Lap: 1}}, []string{
+			"1 firing range visits, expected 2",
+			"skipped firing range 2",
+		}},
+		{"repeated", []models.ShootingStage{{Range: 1, Lap: 1}, {Range: 1, Lap: 2}}, []string{
+			"repeated firing range 1",
+			"skipped firing range 2",
+		}},
+		{"wrong order", []models.ShootingStage{{Range: 2, Lap: 1}, {Range: 1, Lap: 2}}, []string{
+			"stage 1 shot at firing range 2",
+			"stage 2 shot at firing range 1",
+		}},
+		{"same lap", []models.ShootingStage{{Range: 1, Lap: 1}, {Range: 2, Lap: 1}}, []string{
+			"more than 1 firing range visits on lap 1",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues := processor.checkFiringLines(&models.Competitor{ID: 1, Stages: test.stages})
+			if strings.Join(issues, "|") != strings.Join(test.expected, "|") {
+				t.Errorf("Expected %q, got %q", test.expected, issues)
+			}
+		})
+	}
+}
+
+func TestFinisherWithSkippedStageIsFlagged(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.005", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:59:03.872", ""),
+	}
+
+	var report string
+	output := captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	expectedLog := "The competitor(1) has irregular shooting: skipped firing range 1"
+	if !strings.Contains(output, expectedLog) {
+		t.Errorf("Expected log to contain '%s', got: %s", expectedLog, output)
+	}
+	if !strings.Contains(report, "0/0 (0 firing range visits, expected 1; skipped firing range 1)") {
+		t.Errorf("Expected the report to flag the competitor, got: %s", report)
+	}
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"yadro-biathlon/internal/config"
+)
+
+// priorResult is one finisher in a structured results export, e.g.
+// [{"competitor": 1, "time": "00:25:34.773"}, ...].
+type priorResult struct {
+	Competitor int    `json:"competitor"`
+	Time       string `json:"time"`
+}
+
+// LoadResults reads the total times of the finishers of an earlier race, keyed by competitor.
+// The file is either a report written by this tool or a JSON array of priorResult.
+// Competitors who did not start or finish are left out, and so are the sections after the standings.
+func LoadResults(filename string) (map[int]time.Duration, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseResults(data)
+}
+
+// ParseResults parses results as LoadResults does, telling the two formats apart by content.
+func ParseResults(data []byte) (map[int]time.Duration, error) {
+	var export []priorResult
+	if json.Unmarshal(data, &export) == nil {
+		results := make(map[int]time.Duration, len(export))
+		for i, result := range export {
+			total, err := config.ParseDuration(result.Time)
+			if err != nil {
+				return nil, fmt.Errorf("result %d: %w", i+1, err)
+			}
+			results[result.Competitor] = total
+		}
+		return results, nil
+	}
+
+	results := make(map[int]time.Duration)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	standings := false
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			if standings {
+				break
+			}
+			continue
+		}
+		standings = true
+		id, total, ok, err := parseReportLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			results[id] = total
+		}
+	}
+	return results, scanner.Err()
+}
+
+// parseReportLine reads the total time and competitor from a report line like
+// "[00:25:34.773] 3 [...] {,} 10/10". It reports false for empty lines and for
+// competitors without a time.
+func parseReportLine(line string) (int, time.Duration, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0, 0, false, nil
+	}
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "[") || !strings.HasSuffix(fields[0], "]") {
+		return 0, 0, false, fmt.Errorf("expected \"[time] competitor ...\", got %q", line)
+	}
+
+	id, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid competitor %q", fields[1])
+	}
+
+	// Competitors without a result are labelled with their status, e.g. [NotFinished].
+	status := strings.Trim(fields[0], "[]")
+	if status == "" || !unicode.IsDigit(rune(status[0])) {
+		return id, 0, false, nil
+	}
+	// A report written with a layout like "15:04:05,000" separates the fractional seconds by a comma.
+	total, err := config.ParseDuration(strings.Replace(status, ",", ".", 1))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return id, total, true, nil
+}
+
+// Handicaps turns total times into start gaps behind the winner. Gaps longer than
+// limit, if it is positive, are cut to it, so athletes who would be lapped start together.
+func Handicaps(results map[int]time.Duration, limit time.Duration) map[int]time.Duration {
+	var best time.Duration
+	first := true
+	for _, total := range results {
+		if first || total < best {
+			best = total
+			first = false
+		}
+	}
+
+	gaps := make(map[int]time.Duration, len(results))
+	for id, total := range results {
+		gap := total - best
+		if limit > 0 && gap > limit {
+			gap = limit
+		}
+		gaps[id] = gap
+	}
+	return gaps
+}
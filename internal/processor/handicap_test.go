@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+func TestParseResults(t *testing.T) {
+	report := "[00:25:34.773] 3 [{00:12:43.273, 4.586}, {00:12:51.500, 4.537}] {,} 10/10\n" +
+		"[00:27:18.356] 2 [{00:12:39.746, 4.607}, {00:12:38.610, 4.614}] {00:01:40.000, 0.000} 8/10\n" +
+		"[NotFinished] 4 [{00:12:46.947, 4.564}, {,}] {,} 5/5\n" +
+		"[NotStarted] 5 [{,}, {,}] {,} 0/0\n" +
+		"[OnFiringRange] 7 [{00:12:50.000, 4.550}, {,}] {,} 5/10\n" +
+		"[Disqualified] 6 [{00:12:40.000, 4.600}, {00:12:40.000, 4.600}] {00:00:50.000, 3.000} 8/10 (skied 1 of 2 penalty loops)\n" +
+		"\nShooting:\n3 5-5 [{1, 1, prone, 09:49:31.659, 09:49:58.000, xxxxx}]\n"
+	export := `[{"competitor": 3, "time": "00:25:34.773"}, {"competitor": 2, "time": "00:27:18.356"}]`
+
+	expected := map[int]time.Duration{
+		3: 25*time.Minute + 34*time.Second + 773*time.Millisecond,
+		2: 27*time.Minute + 18*time.Second + 356*time.Millisecond,
+	}
+
+	for name, data := range map[string]string{"report": report, "export": export} {
+		results, err := ParseResults([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if len(results) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, results)
+		}
+		for id, total := range expected {
+			if results[id] != total {
+				t.Errorf("%s: expected %v for competitor %d, got %v", name, total, id, results[id])
+			}
+		}
+	}
+
+	results, err := ParseResults([]byte("[00:25:00,500] 3 [{00:12:30,250, 4.867}, {00:12:30,250, 4.867}] {,} 10/10\n"))
+	if err != nil || results[3] != 25*time.Minute+500*time.Millisecond {
+		t.Errorf("Expected a comma separated time to be read, got %v, %v", results, err)
+	}
+
+	if _, err := ParseResults([]byte("[00:25:34.773] 3\ngarbage\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error for line 2, got: %v", err)
+	}
+}
+
+func TestHandicaps(t *testing.T) {
+	results := map[int]time.Duration{
+		1: 25 * time.Minute,
+		2: 25*time.Minute + 42*time.Second,
+		3: 29 * time.Minute,
+	}
+
+	gaps := Handicaps(results, 3*time.Minute)
+	expected := map[int]time.Duration{1: 0, 2: 42 * time.Second, 3: 3 * time.Minute}
+	for id, gap := range expected {
+		if gaps[id] != gap {
+			t.Errorf("Expected gap %v for competitor %d, got %v", gap, id, gaps[id])
+		}
+	}
+
+	if gaps := Handicaps(results, 0); gaps[3] != 4*time.Minute {
+		t.Errorf("Expected an uncapped gap of 4m, got %v", gaps[3])
+	}
+}
+
+func TestPursuitFromPriorResults(t *testing.T) {
+	prior := filepath.Join(t.TempDir(), "sprint")
+	sprint := "[00:25:00.000] 1 [] {,} 10/10\n[00:25:40.000] 2 [] {,} 10/10\n"
+	if err := os.WriteFile(prior, []byte(sprint), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := mustNewEventProcessor(config.Configuration{
+		Laps:         1,
+		LapLen:       3000,
+		PenaltyLen:   150,
+		Start:        "10:00:00.000",
+		StartDelta:   "00:00:30",
+		Format:       config.FormatPursuit,
+		PriorResults: prior,
+	})
+
+	// Competitor 2 starts 40s behind and overtakes competitor 1, who skis faster but finishes later.
+	events := []models.Event{
+		createTestEvent(models.ActionRegistered, 1, "09:05:00.000", ""),
+		createTestEvent(models.ActionRegistered, 2, "09:06:00.000", ""),
+		createTestEvent(models.ActionStartTimeSet, 2, "09:15:00.000", "10:05:00.000"),
+		createTestEvent(models.ActionStarted, 1, "10:00:00.100", ""),
+		createTestEvent(models.ActionStarted, 2, "10:01:10.000", ""),
+		createTestEvent(models.ActionFinishedLap, 2, "10:10:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "10:10:05.000", ""),
+	}
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	if comp := processor.Competitors[2]; comp.PlannedStart.Format(config.TimeFormat) != "10:00:40.000" {
+		t.Errorf("Expected competitor 2 to start at 10:00:40.000, got %s", comp.PlannedStart.Format(config.TimeFormat))
+	}
+
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "[00:09:20.000] 2 ") || !strings.HasPrefix(lines[1], "[00:10:05.000] 1 ") {
+		t.Errorf("Expected competitor 2 first across the line and nobody disqualified, got:\n%s", report)
+	}
+}
+
+func TestPursuitWithoutPriorResultsFails(t *testing.T) {
+	_, err := NewEventProcessor(config.Configuration{
+		Laps:         1,
+		LapLen:       3000,
+		Start:        "10:00:00.000",
+		StartDelta:   "00:00:30",
+		Format:       config.FormatPursuit,
+		PriorResults: filepath.Join(t.TempDir(), "missing"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "prior results") {
+		t.Errorf("Expected an error for missing prior results, got: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/messages"
+	"yadro-biathlon/internal/models"
+)
+
+// loopTimeTolerance is how much faster than the field's typical loop time an athlete may ski
+// a penalty loop. Visits without passing events are credited with every loop that fits into
+// them at that pace, so fast skiers are not taken for skipping one.
+const loopTimeTolerance = 0.25
+
+func (ep *EventProcessor) handlePenaltyLoop(event models.Event, comp *models.Competitor) {
+	comp.LoopsPassed++
+	ep.WriteLog(fmt.Sprintf(messages.PenaltyLoop, event.TimeString, comp.ID, comp.LoopsPassed))
+}
+
+// checkPenaltyLoops compares the penalty loops every finisher skied with the loops they owed,
+// if the configuration asks for it, and applies the configured consequence to those who skied fewer.
+// Visits with passing events count them; the others are estimated from the typical loop time of the field.
+// Each finisher is checked once, so it runs after the race, when the whole field's loop times are known.
+func (ep *EventProcessor) checkPenaltyLoops() {
+	if ep.Config.SkippedLoops == "" {
+		return
+	}
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	typical := ep.typicalLoopTime()
+	var ids []int
+	for id, comp := range ep.Competitors {
+		if comp.Status == models.Finished && !ep.checked[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		comp := ep.Competitors[id]
+		ep.checked[id] = true
+
+		owed := ep.penaltyLoops(comp)
+		skied := ep.skiedLoops(comp, typical)
+		if skied >= owed {
+			continue
+		}
+		comp.SkippedLoops = owed - skied
+		ep.punishSkippedLoops(comp, fmt.Sprintf("skied %d of %d penalty loops", skied, owed))
+	}
+}
+
+// punishSkippedLoops flags a finisher who skipped penalty loops and applies the configured consequence:
+// a time penalty per loop skipped or a disqualification, which takes away the result and
+// leaves a relay leg unfinished.
+func (ep *EventProcessor) punishSkippedLoops(comp *models.Competitor, issue string) {
+	switch ep.Config.SkippedLoops {
+	case config.SkippedLoopsPenalty:
+		perLoop, err := ep.Config.SkippedLoopPenaltyDuration()
+		if err != nil {
+			perLoop = config.DefaultSkippedLoopPenalty
+		}
+		penalty := time.Duration(comp.SkippedLoops) * perLoop
+		comp.TimePenalty += penalty
+		comp.TotalTime += penalty
+		issue += fmt.Sprintf(", +%s", ep.clock.FormatDurationString(penalty))
+	case config.SkippedLoopsDisqualify:
+		comp.Status = models.Disqualified
+		comp.Comment = issue
+		ep.finishLeg(comp, time.Time{})
+	}
+
+	finish := ep.clock.FormatTimeString(comp.FinishTime)
+	comp.Flags = append(comp.Flags, issue)
+	ep.WriteLog(fmt.Sprintf(messages.SkippedLoops, finish, comp.ID, issue))
+	if comp.Status == models.Disqualified {
+		ep.WriteLog(fmt.Sprintf(messages.Disqualified, finish, comp.ID))
+		ep.emit(models.ActionDisqualified, comp.ID, comp.FinishTime)
+	}
+}
+
+// typicalLoopTime returns the median time per penalty loop of the field, or 0 if nobody has skied
+// a penalty loop yet. It is taken from visits with passing events and from visits known to be
+// complete: a visit that skipped loops looks fast per loop owed, so visits without passing events
+// only count if they hold their loops at loopTimeTolerance faster than the median of all visits.
+func (ep *EventProcessor) typicalLoopTime() time.Duration {
+	var passed, owed []time.Duration
+	for _, comp := range ep.Competitors {
+		for _, visit := range comp.PenaltyVisits {
+			switch {
+			case visit.Time <= 0:
+			case visit.Passed > 0:
+				passed = append(passed, visit.Time/time.Duration(visit.Passed))
+			case visit.Loops > 0:
+				owed = append(owed, visit.Time/time.Duration(visit.Loops))
+			}
+		}
+	}
+
+	provisional := median(append(append([]time.Duration{}, passed...), owed...))
+	complete := passed
+	for _, perLoop := range owed {
+		if float64(perLoop) >= float64(provisional)*(1-loopTimeTolerance) {
+			complete = append(complete, perLoop)
+		}
+	}
+	return median(complete)
+}
+
+// median returns the middle of the durations, or 0 if there are none.
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	middle := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[middle-1] + durations[middle]) / 2
+	}
+	return durations[middle]
+}
+
+// skiedLoops returns the number of penalty loops the competitor skied, stage by stage. No stage is
+// credited with more loops than it owed, so a long visit cannot make up for a stage without one.
+func (ep *EventProcessor) skiedLoops(comp *models.Competitor, typical time.Duration) int {
+	skied := 0
+	for i, stage := range comp.Stages {
+		credited := 0
+		for _, visit := range comp.PenaltyVisits {
+			if visit.Stage == i+1 {
+				credited += visitLoops(visit, typical)
+			}
+		}
+		skied += min(credited, ep.rules.PenaltyLoops(stage.Misses()))
+	}
+	return skied
+}
+
+// visitLoops returns the loops skied on a visit: its passing events if there were any, otherwise
+// the loops owed that fit into its time at loopTimeTolerance faster than the typical loop time.
+// Without a typical loop time the loops owed are taken as skied.
+func visitLoops(visit models.PenaltyVisit, typical time.Duration) int {
+	switch {
+	case visit.Passed > 0:
+		return visit.Passed
+	case typical > 0:
+		return min(int(math.Floor(float64(visit.Time)/(float64(typical)*(1-loopTimeTolerance)))), visit.Loops)
+	}
+	return visit.Loops
+}
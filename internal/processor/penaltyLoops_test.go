@@ -0,0 +1,160 @@
+package processor
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+// loopRace returns the events of a one lap race in which the competitor hits the given number
+// of the 5 targets and spends penalty on the penalty loops, reporting passes passing events.
+func loopRace(id, hits int, penalty time.Duration, passes int) []models.Event {
+	start, _ := time.Parse(config.TimeFormat, "09:30:00.000")
+	start = start.Add(time.Duration(id) * time.Minute)
+	at := func(d time.Duration) string { return start.Add(d).Format(config.TimeFormat) }
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, id, "09:15:00.000", at(0)),
+		createTestEvent(models.ActionStarted, id, at(time.Second), ""),
+		createTestEvent(models.ActionOnFiringRange, id, at(5*time.Minute), "1"),
+	}
+	for target := 1; target <= hits; target++ {
+		events = append(events, createTestEvent(models.ActionHit, id, at(5*time.Minute+time.Duration(target)*time.Second), strconv.Itoa(target)))
+	}
+	events = append(events,
+		createTestEvent(models.ActionLeftFiringRange, id, at(5*time.Minute+30*time.Second), ""),
+		createTestEvent(models.ActionOnPenaltyLaps, id, at(6*time.Minute), ""))
+	for pass := 1; pass <= passes; pass++ {
+		events = append(events, createTestEvent(models.ActionPenaltyLoop, id, at(6*time.Minute+time.Duration(pass)*10*time.Second), ""))
+	}
+	return append(events,
+		createTestEvent(models.ActionLeftPenaltyLaps, id, at(6*time.Minute+penalty), ""),
+		createTestEvent(models.ActionFinishedLap, id, at(10*time.Minute), ""))
+}
+
+// loopField is a race with a typical penalty loop time of 30 seconds. Competitor 4 skis one
+// of their two loops, and competitor 5 passes only one loop, however long they take over it.
+// Competitor 6 skis all three of their loops, about 17% faster than the field.
+func loopField() []models.Event {
+	var events []models.Event
+	events = append(events, loopRace(1, 3, 60*time.Second, 0)...)
+	events = append(events, loopRace(2, 3, 62*time.Second, 0)...)
+	events = append(events, loopRace(3, 2, 90*time.Second, 0)...)
+	events = append(events, loopRace(4, 3, 30*time.Second, 0)...)
+	events = append(events, loopRace(5, 3, 58*time.Second, 1)...)
+	events = append(events, loopRace(6, 2, 75*time.Second, 0)...)
+	return events
+}
+
+func runLoopField(skippedLoops string) (*EventProcessor, string) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.SkippedLoops = skippedLoops
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(loopField())
+		report = processor.GenerateReport()
+		processor.GenerateReport()
+	})
+	return processor, report
+}
+
+func TestSkippedLoopsFlagged(t *testing.T) {
+	processor, report := runLoopField(config.SkippedLoopsFlag)
+
+	for id, skipped := range map[int]int{1: 0, 2: 0, 3: 0, 4: 1, 5: 1, 6: 0} {
+		if comp := processor.Competitors[id]; comp.SkippedLoops != skipped {
+			t.Errorf("Expected competitor %d to skip %d loops, got %d", id, skipped, comp.SkippedLoops)
+		}
+	}
+	if !strings.Contains(report, "(skied 1 of 2 penalty loops)") {
+		t.Errorf("Expected the skipped loop to be flagged, got: %s", report)
+	}
+	if processor.Competitors[4].Status != models.Finished {
+		t.Errorf("Expected flagging alone not to disqualify")
+	}
+}
+
+func TestSkippedLoopsNotCheckedByDefault(t *testing.T) {
+	processor, _ := runLoopField("")
+	if processor.Competitors[4].SkippedLoops != 0 || len(processor.Competitors[4].Flags) != 0 {
+		t.Errorf("Expected no check without skippedLoops, got %v", processor.Competitors[4].Flags)
+	}
+}
+
+func TestSkippedLoopsPenalty(t *testing.T) {
+	processor, report := runLoopField(config.SkippedLoopsPenalty)
+
+	// Competitor 4 finishes in 00:10:00 and gets the default 2 minutes for the loop, once
+	// however many times the report is generated.
+	comp := processor.Competitors[4]
+	if expected := 12 * time.Minute; comp.TotalTime != expected {
+		t.Errorf("Expected total time %v, got %v", expected, comp.TotalTime)
+	}
+	if !strings.Contains(report, "(skied 1 of 2 penalty loops, +00:02:00.000)") {
+		t.Errorf("Expected the penalty to be flagged, got: %s", report)
+	}
+}
+
+func TestSkippedLoopsDisqualify(t *testing.T) {
+	processor, report := runLoopField(config.SkippedLoopsDisqualify)
+
+	for _, id := range []int{4, 5} {
+		if processor.Competitors[id].Status != models.Disqualified {
+			t.Errorf("Expected competitor %d to be disqualified, got %v", id, processor.Competitors[id].Status)
+		}
+		if !processor.emitted(models.ActionDisqualified, id) {
+			t.Errorf("Expected a disqualification event for competitor %d", id)
+		}
+	}
+	if !strings.Contains(report, "[Disqualified] 4") || strings.Contains(report, "[NotFinished]") {
+		t.Errorf("Expected competitor 4 to be listed as disqualified, got: %s", report)
+	}
+	if processor.Competitors[6].Status != models.Finished {
+		t.Errorf("Expected the fast skier to keep their result, got %v", processor.Competitors[6].Status)
+	}
+}
+
+func TestSkippedLoopsNotCoveredByAnotherStage(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.SkippedLoops = config.SkippedLoopsFlag
+
+	// Competitor 7 misses one target at each of two stages and spends the time of three loops
+	// after the first, but skips the loop of the second.
+	events := append(loopField(),
+		createTestEvent(models.ActionStartTimeSet, 7, "09:15:00.000", "09:37:00.000"),
+		createTestEvent(models.ActionStarted, 7, "09:37:01.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 7, "09:42:00.000", "1"))
+	for target := 1; target <= 4; target++ {
+		events = append(events, createTestEvent(models.ActionHit, 7, "09:42:0"+strconv.Itoa(target)+".000", strconv.Itoa(target)))
+	}
+	events = append(events,
+		createTestEvent(models.ActionLeftFiringRange, 7, "09:42:30.000", ""),
+		createTestEvent(models.ActionOnPenaltyLaps, 7, "09:43:00.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 7, "09:44:30.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 7, "09:45:00.000", "1"))
+	for target := 1; target <= 4; target++ {
+		events = append(events, createTestEvent(models.ActionHit, 7, "09:45:0"+strconv.Itoa(target)+".000", strconv.Itoa(target)))
+	}
+	events = append(events,
+		createTestEvent(models.ActionLeftFiringRange, 7, "09:45:30.000", ""),
+		createTestEvent(models.ActionFinishedLap, 7, "09:47:00.000", ""))
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	if comp := processor.Competitors[7]; comp.SkippedLoops != 1 {
+		t.Errorf("Expected competitor 7 to skip the loop of the second stage, got %d in: %s", comp.SkippedLoops, report)
+	}
+	if !strings.Contains(report, "(skied 1 of 2 penalty loops)") {
+		t.Errorf("Expected the skipped loop to be flagged, got: %s", report)
+	}
+}
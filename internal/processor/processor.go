@@ -3,9 +3,9 @@ package processor
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,22 +18,81 @@ import (
 // EventProcessor manages the lifecycle of competitor events in a biathlon race.
 // It collects events, updates competitor state, logs progress, and generates the final report.
 type EventProcessor struct {
+	// Strict makes the first anomaly fatal: processing stops and Err returns it.
+	// Otherwise anomalies are recorded and the events applied anyway.
+	Strict bool
+
+	// ShowTargets adds the targets of every stage to the standings, e.g. x.xx. when targets 2 and 5 were missed.
+	ShowTargets bool
+
+	// ShowDistance adds {total distance, average speed} to the standings of every finisher:
+	// the laps by their own lengths plus the penalty loops owed, over the time from the race start.
+	ShowDistance bool
+
+	// ShowStages appends a Shooting section to the final report with every competitor's stages.
+	ShowStages bool
+
+	// ShowRangeTimes appends a Range time section to the final report ranking the field by time on the range.
+	ShowRangeTimes bool
+
 	Config      config.Configuration
 	Competitors map[int]*models.Competitor
+	Teams       map[int]*models.Team // relay teams by ID
 	Events      []models.Event
+	Anomalies   []*Anomaly
+	err         *Anomaly
 	logFile     *os.File
 	logWriter   *bufio.Writer
+	rules       Rules
+	reorder     *reorderBuffer
+	timeline    *utils.Timeline
+	clock       utils.Clock
+	latest      time.Time
+	hasLatest   bool
+	checked     map[int]bool // finishers whose penalty loops were checked
 	mu          sync.Mutex
 }
 
 // NewEventProcessor creates an EventProcessor with the given configuration.
-// Initializes internal maps and event slice.
-func NewEventProcessor(config config.Configuration) *EventProcessor {
-	return &EventProcessor{
-		Config:      config,
+// Initializes internal maps and event slice, the clock and timeline of the race,
+// the rules of the race format, the relay teams, and the reorder buffer if a reorder window is configured.
+// It returns an error if any of them cannot be set up from the configuration, e.g. when the
+// prior results of a pursuit cannot be read, rather than process the race under other rules.
+func NewEventProcessor(conf config.Configuration) (*EventProcessor, error) {
+	ep := &EventProcessor{
+		Config:      conf,
 		Competitors: make(map[int]*models.Competitor),
+		Teams:       make(map[int]*models.Team),
 		Events:      []models.Event{},
+		checked:     make(map[int]bool),
+	}
+
+	clock, err := utils.NewClock(conf)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %s: %w", conf.TimeZone, err)
+	}
+	ep.clock = clock
+
+	date, err := conf.RaceDate()
+	if err != nil {
+		return nil, fmt.Errorf("race date %s: %w", conf.Date, err)
 	}
+	ep.timeline = utils.NewTimeline(date)
+
+	ep.rules, err = NewRules(conf)
+	if err != nil {
+		return nil, fmt.Errorf("race format %s: %w", conf.RaceFormat(), err)
+	}
+	ep.setupTeams()
+
+	if conf.ReorderWindow != "" {
+		window, err := config.ParseDuration(conf.ReorderWindow)
+		if err != nil {
+			return nil, fmt.Errorf("reorder window: %w", err)
+		}
+		ep.reorder = newReorderBuffer(window)
+	}
+	return ep, nil
 }
 
 // WriteLog outputs a log line to stdout and, if enabled, to the log file.
@@ -77,18 +136,95 @@ func (ep *EventProcessor) EnableLogFile(filename string) error {
 	return nil
 }
 
+// EventSource yields events one at a time, as events.Reader does.
+// Read returns io.EOF when no more events will arrive.
+type EventSource interface {
+	Read() (models.Event, error)
+}
+
 // ProcessEvent routes a single event to its handler based on event.Action.
 // Updates competitor state and appends the event to history.
 func (ep *EventProcessor) ProcessEvent(event models.Event) {
-	comp, exists := ep.Competitors[event.CompetitorID]
-	if !exists {
-		comp = &models.Competitor{
-			ID:         event.CompetitorID,
-			Status:     models.Registered,
-			LapsResult: make([]models.LapResult, 0, ep.Config.Laps),
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	ep.processEvent(event)
+}
+
+// Submit accepts an event as it arrives from the timing system and returns the events it processed.
+// The event's clock time is first placed on the race timeline, moving to the next day after midnight.
+// With a reorder window configured, events are held back and released in time order; an event
+// arriving too late to be reordered is rejected and logged. Without one, events are processed
+// immediately and timestamps going backwards are only reported.
+func (ep *EventProcessor) Submit(event models.Event) []models.Event {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.err != nil {
+		return nil
+	}
+
+	event.Time = ep.timeline.Place(event.Time)
+
+	if ep.reorder == nil {
+		if ep.hasLatest && event.Time.Before(ep.latest) {
+			ep.WriteLog(fmt.Sprintf(messages.EventOutOfOrder, event.TimeString, event.Action, event.CompetitorID,
+				ep.latest.Sub(event.Time)))
+		} else {
+			ep.latest = event.Time
+			ep.hasLatest = true
+		}
+		ep.processEvent(event)
+		return []models.Event{event}
+	}
+
+	ready, err := ep.reorder.push(event)
+	if err != nil {
+		ep.WriteLog(fmt.Sprintf(messages.EventTooLate, event.TimeString, event.Action, event.CompetitorID, err))
+		return nil
+	}
+	for _, e := range ready {
+		ep.processEvent(e)
+	}
+	return ready
+}
+
+// Flush processes the events still held by the reorder buffer and returns them.
+// It must be called once the input has ended.
+func (ep *EventProcessor) Flush() []models.Event {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.reorder == nil {
+		return nil
+	}
+
+	ready := ep.reorder.flush()
+	for _, e := range ready {
+		ep.processEvent(e)
+	}
+	return ready
+}
+
+func (ep *EventProcessor) processEvent(event models.Event) {
+	// Outgoing events read back from an earlier run are kept in the history only:
+	// the processor derives finishes and disqualifications itself and must not apply them twice.
+	if event.Action.Outgoing() {
+		if !ep.emitted(event.Action, event.CompetitorID) {
+			ep.Events = append(ep.Events, event)
 		}
-		ep.Competitors[event.CompetitorID] = comp
+		return
+	}
+
+	if ep.err != nil {
+		return
+	}
+
+	comp := ep.competitor(event.CompetitorID)
+	if !ep.checkTransition(event, comp) {
+		return
 	}
+	ep.Events = append(ep.Events, event)
 
 	switch event.Action {
 	case models.ActionRegistered:
@@ -113,9 +249,60 @@ func (ep *EventProcessor) ProcessEvent(event models.Event) {
 		ep.handleFinishedLap(event, comp)
 	case models.ActionCannotContinue:
 		ep.handleCannotContinue(event, comp)
+	case models.ActionSpareRound:
+		ep.handleSpareRound(event, comp)
+	case models.ActionExchange:
+		ep.handleExchange(event, comp)
+	case models.ActionPenaltyLoop:
+		ep.handlePenaltyLoop(event, comp)
 	}
+}
 
-	ep.Events = append(ep.Events, event)
+// competitor returns the competitor with the given ID, registering it on first sight.
+func (ep *EventProcessor) competitor(id int) *models.Competitor {
+	comp, exists := ep.Competitors[id]
+	if !exists {
+		comp = &models.Competitor{
+			ID:         id,
+			Status:     models.Registered,
+			LapsResult: make([]models.LapResult, 0, ep.Config.Laps),
+		}
+		if start, ok := ep.rules.AssignedStart(comp.ID); ok {
+			comp.PlannedStart = start
+			comp.LapStartTime = start
+		}
+		ep.Competitors[id] = comp
+	}
+	return comp
+}
+
+// emit appends an outgoing event to the history unless the same outcome is already recorded,
+// either from an earlier call or from re-ingested input.
+func (ep *EventProcessor) emit(action models.Action, competitorID int, t time.Time) {
+	if ep.emitted(action, competitorID) {
+		return
+	}
+	ep.Events = append(ep.Events, models.Event{
+		Time:         t,
+		TimeString:   ep.clock.FormatTimeString(t),
+		Action:       action,
+		CompetitorID: competitorID,
+	})
+}
+
+// flag records an irregularity on the competitor and logs it with the given message.
+func (ep *EventProcessor) flag(comp *models.Competitor, event models.Event, message, issue string) {
+	comp.Flags = append(comp.Flags, issue)
+	ep.WriteLog(fmt.Sprintf(message, event.TimeString, comp.ID, issue))
+}
+
+func (ep *EventProcessor) emitted(action models.Action, competitorID int) bool {
+	for _, e := range ep.Events {
+		if e.Action == action && e.CompetitorID == competitorID {
+			return true
+		}
+	}
+	return false
 }
 
 // Individual handlers update competitor state and log each specific action.
@@ -127,14 +314,22 @@ func (ep *EventProcessor) handleRegistered(event models.Event, comp *models.Comp
 }
 
 func (ep *EventProcessor) handleStartTimeSet(event models.Event, comp *models.Competitor) {
-	startTime, err := time.Parse(config.TimeFormat, event.ExtraParams)
-	if err != nil {
-		fmt.Printf("Error parsing start time: %v\n", err)
+	payload, ok := event.Payload.(models.StartTimePayload)
+	if !ok {
+		fmt.Printf("Error setting start time: event has no start time\n")
+		return
+	}
+	comp.Status = models.Registered
+	if start, assigned := ep.rules.AssignedStart(comp.ID); assigned {
+		ep.WriteLog(fmt.Sprintf(messages.StartTimeAssigned, event.TimeString, event.ExtraParams, comp.ID,
+			ep.clock.Format(start)))
 		return
 	}
+
+	// The draw only gives a clock time; it belongs to the day of the event that announced it.
+	startTime := utils.Near(payload.Start, event.Time)
 	comp.PlannedStart = startTime
 	comp.LapStartTime = startTime
-	comp.Status = models.Registered
 	ep.WriteLog(fmt.Sprintf(messages.StartTimeSet, event.TimeString, comp.ID, event.ExtraParams))
 }
 
@@ -151,38 +346,100 @@ func (ep *EventProcessor) handleStarted(event models.Event, comp *models.Competi
 }
 
 func (ep *EventProcessor) handleOnFiringRange(event models.Event, comp *models.Competitor) {
+	payload, _ := event.Payload.(models.FiringRangePayload)
+	stage := len(comp.Stages)
+	comp.Stages = append(comp.Stages, models.ShootingStage{
+		Range:      payload.Range,
+		Lap:        comp.CurrentLap,
+		Position:   ep.rules.Position(stage),
+		Targets:    ep.Config.StageTargets(stage),
+		Rounds:     ep.Config.StageRounds(stage),
+		TargetHits: make([]bool, ep.Config.StageTargets(stage)),
+		Arrival:    event.Time,
+	})
 	comp.Status = models.OnFiringRange
 	ep.WriteLog(fmt.Sprintf(messages.OnFiringRange, event.TimeString, comp.ID, event.ExtraParams))
 }
 
+// handleHit counts a hit target of the current range visit. Hits outside a visit,
+// on targets the stage does not have, or on targets already hit are flagged and not counted.
 func (ep *EventProcessor) handleHit(event models.Event, comp *models.Competitor) {
+	ep.WriteLog(fmt.Sprintf(messages.TargetHit, event.TimeString, event.ExtraParams, comp.ID))
+
+	if comp.Status != models.OnFiringRange || len(comp.Stages) == 0 {
+		ep.flag(comp, event, messages.IrregularShooting, fmt.Sprintf("target %s hit outside a firing range visit", event.ExtraParams))
+		return
+	}
+
+	payload, _ := event.Payload.(models.TargetPayload)
+	stage := &comp.Stages[len(comp.Stages)-1]
+	switch {
+	case payload.Target < 1 || payload.Target > len(stage.TargetHits):
+		ep.flag(comp, event, messages.IrregularShooting,
+			fmt.Sprintf("target %d hit at stage %d, which has %d targets", payload.Target, len(comp.Stages), len(stage.TargetHits)))
+		return
+	case stage.TargetHits[payload.Target-1]:
+		ep.flag(comp, event, messages.IrregularShooting,
+			fmt.Sprintf("target %d hit twice at stage %d", payload.Target, len(comp.Stages)))
+		return
+	}
+
+	stage.TargetHits[payload.Target-1] = true
+	stage.Hits++
+	stage.ShootingTime = event.Time.Sub(stage.Arrival)
 	comp.Hits++
 	comp.LastFiringHits++
-	ep.WriteLog(fmt.Sprintf(messages.TargetHit, event.TimeString, event.ExtraParams, comp.ID))
 }
 
 func (ep *EventProcessor) handleLeftFiringRange(event models.Event, comp *models.Competitor) {
-	comp.Shots += 5
+	if len(comp.Stages) > 0 {
+		stage := &comp.Stages[len(comp.Stages)-1]
+		stage.Departure = event.Time
+		stage.RangeTime = event.Time.Sub(stage.Arrival)
+		comp.Shots += stage.Targets + stage.Spares
+		comp.TimePenalty += ep.rules.TimePenalty(stage.Misses())
+	} else {
+		comp.Shots += ep.Config.StageTargets(0)
+	}
 	comp.Status = models.LeftFiringRange
 	ep.WriteLog(fmt.Sprintf(messages.LeftFiringRange, event.TimeString, comp.ID))
 }
 
 func (ep *EventProcessor) handleOnPenaltyLaps(event models.Event, comp *models.Competitor) {
 	comp.PenaltyStartTime = event.Time
+	comp.LoopsPassed = 0
 	comp.Status = models.OnPenaltyLaps
 	ep.WriteLog(fmt.Sprintf(messages.EnteredPenaltyLaps, event.TimeString, comp.ID))
 }
 
+// handleLeftPenaltyLaps records the visit to the penalty loops for the misses of the last stage
+// and updates the totals, which only count completed visits.
 func (ep *EventProcessor) handleLeftPenaltyLaps(event models.Event, comp *models.Competitor) {
-	penaltyTime := event.Time.Sub(comp.PenaltyStartTime)
-	allMisses := comp.Shots - comp.Hits
-	penaltyDistance := allMisses * ep.Config.PenaltyLen
-
-	comp.FullPenaltyTime += penaltyTime
+	visit := models.PenaltyVisit{Time: event.Time.Sub(comp.PenaltyStartTime), Passed: comp.LoopsPassed}
+	if len(comp.Stages) > 0 {
+		stage := comp.Stages[len(comp.Stages)-1]
+		visit.Stage = len(comp.Stages)
+		visit.Misses = stage.Misses()
+	} else {
+		visit.Misses = comp.Shots - comp.Hits - spareRounds(comp)
+		for _, earlier := range comp.PenaltyVisits {
+			visit.Misses -= earlier.Misses
+		}
+	}
+	visit.Loops = ep.rules.PenaltyLoops(visit.Misses)
+	if visit.Time.Seconds() > 0 {
+		visit.Speed = float64(visit.Loops*ep.Config.PenaltyLen) / visit.Time.Seconds()
+	}
+	comp.PenaltyVisits = append(comp.PenaltyVisits, visit)
 
+	comp.FullPenaltyTime += visit.Time
+	loops := 0
+	for _, v := range comp.PenaltyVisits {
+		loops += v.Loops
+	}
 	var speed float64
 	if comp.FullPenaltyTime.Seconds() > 0 {
-		speed = float64(penaltyDistance) / comp.FullPenaltyTime.Seconds()
+		speed = float64(loops*ep.Config.PenaltyLen) / comp.FullPenaltyTime.Seconds()
 	}
 	comp.PenaltyResult = models.PenaltyResult{Time: comp.FullPenaltyTime, Speed: speed}
 	comp.Status = models.LeftPenaltyLaps
@@ -191,106 +448,153 @@ func (ep *EventProcessor) handleLeftPenaltyLaps(event models.Event, comp *models
 
 func (ep *EventProcessor) handleFinishedLap(event models.Event, comp *models.Competitor) {
 	lapTime := event.Time.Sub(comp.LapStartTime)
-	lastPenaltyDistance := (5 - comp.LastFiringHits) * ep.Config.PenaltyLen
+	lastPenaltyDistance := ep.rules.PenaltyLoops(ep.lapMisses(comp)) * ep.Config.PenaltyLen
 	comp.LastFiringHits = 0
 
-	speed := (float64(ep.Config.LapLen) + float64(lastPenaltyDistance)) / lapTime.Seconds()
+	speed := (float64(ep.Config.LapLength(comp.CurrentLap)) + float64(lastPenaltyDistance)) / lapTime.Seconds()
 	comp.LapsResult = append(comp.LapsResult, models.LapResult{Time: lapTime, Speed: speed})
 	comp.Status = models.FinishedLap
 	ep.WriteLog(fmt.Sprintf(messages.MainLapEnded, event.TimeString, comp.ID))
 
 	if comp.CurrentLap >= ep.Config.Laps {
 		comp.Status = models.Finished
-		comp.TotalTime = event.Time.Sub(comp.PlannedStart)
+		comp.FinishTime = event.Time
+		comp.TotalTime = event.Time.Sub(ep.rules.RaceStart(comp)) + comp.TimePenalty
 		ep.WriteLog(fmt.Sprintf(messages.Finished, event.TimeString, comp.ID))
+		ep.emit(models.ActionFinished, comp.ID, event.Time)
+		ep.finishLeg(comp, event.Time)
+
+		for _, issue := range ep.checkFiringLines(comp) {
+			ep.flag(comp, event, messages.IrregularShooting, issue)
+		}
 	} else {
 		comp.CurrentLap++
 		comp.LapStartTime = event.Time
 	}
 }
 
-func (ep *EventProcessor) handleCannotContinue(event models.Event, comp *models.Competitor) {
-	comp.Status = models.NotFinished
-	ep.WriteLog(fmt.Sprintf(messages.CannotContinue, event.TimeString, comp.ID, event.ExtraParams))
+// distance returns the metres a finisher covered, laps and penalty loops, and their average speed.
+func (ep *EventProcessor) distance(comp *models.Competitor) (int, float64) {
+	distance := ep.Config.CourseLength() + ep.penaltyLoops(comp)*ep.Config.PenaltyLen
+	raceTime := comp.FinishTime.Sub(ep.rules.RaceStart(comp))
+	if raceTime <= 0 {
+		return distance, 0
+	}
+	return distance, float64(distance) / raceTime.Seconds()
 }
 
-// CheckDisqualifications inspects actual start times against allowed delta and disqualifies late/no-shows.
-func (ep *EventProcessor) CheckDisqualifications() {
-	deltaTimeStr := ep.Config.StartDelta
+// lapMisses returns the targets the competitor left standing at the stages of the current lap.
+// Without any recorded firing range arrival the hits of the lap are taken against one stage.
+func (ep *EventProcessor) lapMisses(comp *models.Competitor) int {
+	if len(comp.Stages) == 0 {
+		return ep.Config.StageTargets(0) - comp.LastFiringHits
+	}
 
-	deltaParts := strings.Split(deltaTimeStr, ":")
-	if len(deltaParts) != 3 {
-		fmt.Printf("Not correct delta time: %s\n", deltaTimeStr)
-		return
+	misses := 0
+	for _, stage := range comp.Stages {
+		if stage.Lap == comp.CurrentLap {
+			misses += stage.Misses()
+		}
 	}
+	return misses
+}
 
-	h, _ := strconv.Atoi(deltaParts[0])
-	m, _ := strconv.Atoi(deltaParts[1])
-	s, _ := strconv.Atoi(deltaParts[2])
+func (ep *EventProcessor) handleCannotContinue(event models.Event, comp *models.Competitor) {
+	comp.Status = models.NotFinished
+	ep.WriteLog(fmt.Sprintf(messages.CannotContinue, event.TimeString, comp.ID, event.ExtraParams))
+}
 
-	startDeltaDuration := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+// CheckDisqualifications disqualifies the competitors who missed the start under the rules of the race format:
+// late starts and no-shows in interval starts, no-shows otherwise.
+func (ep *EventProcessor) CheckDisqualifications() {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
 
 	for _, comp := range ep.Competitors {
-		endStartInterval := comp.PlannedStart.Add(startDeltaDuration)
-		if comp.ActualStart.IsZero() || comp.ActualStart.After(endStartInterval) || comp.ActualStart.Before(comp.PlannedStart) {
+		if disqualificationTime, ok := ep.rules.Disqualified(comp); ok {
 			comp.Status = models.NotStarted
-			disqualificationTimeStr := utils.FormatTimeString(endStartInterval.Add(time.Millisecond))
-			ep.WriteLog(fmt.Sprintf(messages.Disqualified, disqualificationTimeStr, comp.ID))
+			ep.WriteLog(fmt.Sprintf(messages.Disqualified, ep.clock.FormatTimeString(disqualificationTime), comp.ID))
+			ep.emit(models.ActionDisqualified, comp.ID, disqualificationTime)
 		}
-
 	}
 }
 
-func (ep *EventProcessor) ProcessEvents(events []models.Event) {
+// ProcessEvents processes a batch of already loaded events in arrival order.
+// In strict mode it returns the anomaly that stopped processing.
+func (ep *EventProcessor) ProcessEvents(events []models.Event) error {
 	for _, event := range events {
-		ep.ProcessEvent(event)
+		ep.Submit(event)
 	}
+	ep.Flush()
+	return ep.Err()
 }
 
-// GenerateReport sorts competitors, includes lap and penalty results, and returns formatted report.
-func (ep *EventProcessor) GenerateReport() string {
-	ep.CheckDisqualifications()
+// ProcessStream consumes events from src as they arrive and processes each one immediately.
+// If update is not nil, it is called after every processed event so callers can refresh live standings.
+// It returns nil once src reports io.EOF, or in strict mode the first anomaly.
+func (ep *EventProcessor) ProcessStream(src EventSource, update func(models.Event)) error {
+	for {
+		event, err := src.Read()
+		if err == io.EOF {
+			ep.notify(ep.Flush(), update)
+			return ep.Err()
+		}
+		if err != nil {
+			return err
+		}
 
-	var sortedCompetitors []*models.Competitor
-	for _, comp := range ep.Competitors {
-		sortedCompetitors = append(sortedCompetitors, comp)
+		ep.notify(ep.Submit(event), update)
+		if err := ep.Err(); err != nil {
+			return err
+		}
 	}
+}
 
-	sort.Slice(sortedCompetitors, func(i, j int) bool {
-		a, b := sortedCompetitors[i], sortedCompetitors[j]
+func (ep *EventProcessor) notify(processed []models.Event, update func(models.Event)) {
+	if update == nil {
+		return
+	}
+	for _, event := range processed {
+		update(event)
+	}
+}
 
-		if a.Status == models.NotStarted && b.Status == models.NotStarted {
-			return a.ID < b.ID
-		}
-		if a.Status == models.NotStarted {
-			return false
-		}
-		if b.Status == models.NotStarted {
-			return true
-		}
+// GenerateReport applies disqualifications and returns the final results table,
+// followed by the anomalies found in the events.
+func (ep *EventProcessor) GenerateReport() string {
+	ep.CheckDisqualifications()
+	ep.checkPenaltyLoops()
+	report := ep.Standings()
 
-		if a.Status == models.NotFinished && b.Status == models.NotFinished {
-			return a.ID < b.ID
-		}
-		if a.Status == models.NotFinished {
-			return false
-		}
-		if b.Status == models.NotFinished {
-			return true
-		}
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if ep.ShowStages {
+		report += ep.shootingReport()
+	}
+	if ep.ShowRangeTimes {
+		report += ep.rangeTimeReport()
+	}
+	return report + ep.anomalyReport()
+}
 
-		return a.TotalTime < b.TotalTime
-	})
+// Standings sorts competitors, includes lap and penalty results, and returns the formatted table.
+// The penalty loop totals are followed by the hits and then the split of every visit to the
+// penalty loops as {stage, time, speed}.
+// Finishers are ranked by the race format, on adjusted time unless it says otherwise; in formats
+// that penalise misses with time the penalty is listed in its own column after the penalty loops.
+// Competitors without a result are labelled with their status instead of a time, e.g. [OnFiringRange]
+// for one still racing. Unlike GenerateReport it does not disqualify anyone, so it is safe to call
+// while the race is running.
+func (ep *EventProcessor) Standings() string {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
 
 	var report strings.Builder
-	for _, comp := range sortedCompetitors {
-		switch comp.Status {
-		case models.NotStarted:
-			report.WriteString(fmt.Sprintf("[NotStarted] %d", comp.ID))
-		case models.NotFinished:
-			report.WriteString(fmt.Sprintf("[NotFinished] %d", comp.ID))
-		default:
-			report.WriteString(fmt.Sprintf("[%s] %d", utils.FormatDurationString(comp.TotalTime), comp.ID))
+	for _, comp := range ep.ranked() {
+		if comp.Status == models.Finished {
+			report.WriteString(fmt.Sprintf("[%s] %d", ep.clock.FormatDurationString(comp.TotalTime), comp.ID))
+		} else {
+			report.WriteString(fmt.Sprintf("[%s] %d", comp.Status, comp.ID))
 		}
 
 		report.WriteString(" [")
@@ -300,26 +604,104 @@ func (ep *EventProcessor) GenerateReport() string {
 			}
 			if i < len(comp.LapsResult) {
 				lapResult := comp.LapsResult[i]
-				report.WriteString(fmt.Sprintf("{%s, %.3f}", utils.FormatDurationString(lapResult.Time), lapResult.Speed))
+				report.WriteString(fmt.Sprintf("{%s, %.3f}", ep.clock.FormatDurationString(lapResult.Time), lapResult.Speed))
 			} else {
 				report.WriteString("{,}")
 			}
 		}
 		report.WriteString("]")
-		if comp.Hits != comp.Shots {
-			report.WriteString(fmt.Sprintf(" {%s, %.3f}", utils.FormatDurationString(comp.PenaltyResult.Time), comp.PenaltyResult.Speed))
+		// Misses in a timed format are punished by TimePenalty, not penalty loops.
+		if !ep.rules.Timed() && comp.Hits+spareRounds(comp) != comp.Shots {
+			report.WriteString(fmt.Sprintf(" {%s, %.3f}", ep.clock.FormatDurationString(comp.PenaltyResult.Time), comp.PenaltyResult.Speed))
 		} else {
 			report.WriteString(" {,}")
 		}
+		if ep.rules.Timed() {
+			report.WriteString(fmt.Sprintf(" +%s", ep.clock.FormatDurationString(comp.TimePenalty)))
+		}
 
 		report.WriteString(fmt.Sprintf(" %d/%d", comp.Hits, comp.Shots))
 
+		if len(comp.PenaltyVisits) > 0 {
+			report.WriteString(" [")
+			for i, visit := range comp.PenaltyVisits {
+				if i > 0 {
+					report.WriteString(", ")
+				}
+				report.WriteString(fmt.Sprintf("{%d, %s, %.3f}", visit.Stage, ep.clock.FormatDurationString(visit.Time), visit.Speed))
+			}
+			report.WriteString("]")
+		}
+
+		if ep.ShowDistance {
+			if comp.Status == models.Finished {
+				distance, speed := ep.distance(comp)
+				report.WriteString(fmt.Sprintf(" {%d, %.3f}", distance, speed))
+			} else {
+				report.WriteString(" {,}")
+			}
+		}
+
+		if ep.ShowTargets && len(comp.Stages) > 0 {
+			patterns := make([]string, len(comp.Stages))
+			for i, stage := range comp.Stages {
+				patterns[i] = stage.Pattern()
+			}
+			report.WriteString(" [" + strings.Join(patterns, ", ") + "]")
+		}
+
+		if len(comp.Flags) > 0 {
+			report.WriteString(" (" + strings.Join(comp.Flags, "; ") + ")")
+		}
+
+		report.WriteString("\n")
+	}
+
+	if len(ep.Teams) > 0 {
 		report.WriteString("\n")
+		report.WriteString(ep.teamStandings())
 	}
 
 	return report.String()
 }
 
+// ranked returns the competitors in the order of the standings: finishers by the race format,
+// then, by ID, those still racing, those who did not finish, those disqualified after finishing,
+// and those who did not start.
+func (ep *EventProcessor) ranked() []*models.Competitor {
+	var ranked []*models.Competitor
+	for _, comp := range ep.Competitors {
+		ranked = append(ranked, comp)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if groupA, groupB := standingsGroup(a.Status), standingsGroup(b.Status); groupA != groupB {
+			return groupA < groupB
+		}
+		if a.Status == models.Finished {
+			return ep.rules.Less(a, b)
+		}
+		return a.ID < b.ID
+	})
+	return ranked
+}
+
+// standingsGroup returns the position of a competitor's group in the standings.
+func standingsGroup(status models.CompetitorStatus) int {
+	switch status {
+	case models.Finished:
+		return 0
+	case models.NotFinished:
+		return 2
+	case models.Disqualified:
+		return 3
+	case models.NotStarted:
+		return 4
+	}
+	return 1
+}
+
 // SaveReport writes the report string to a file by name.
 func (ep *EventProcessor) SaveReport(filename string) error {
 	report := ep.GenerateReport()
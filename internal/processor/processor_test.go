@@ -2,6 +2,7 @@ package processor
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"math"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/events"
 	"yadro-biathlon/internal/models"
 	"yadro-biathlon/internal/utils"
 )
@@ -46,13 +48,17 @@ func TestGenerateReport(t *testing.T) {
 		Laps:       2,
 		LapLen:     3651,
 		PenaltyLen: 50,
+		StartDelta: "00:00:30",
 	}
 
-	processor := NewEventProcessor(conf)
+	processor := mustNewEventProcessor(conf)
 
+	start, _ := time.Parse(config.TimeFormat, "09:30:00.000")
 	processor.Competitors[1] = &models.Competitor{
 		ID:            1,
 		Status:        models.NotFinished,
+		PlannedStart:  start,
+		ActualStart:   start,
 		LapsResult:    []models.LapResult{{Time: 29*time.Minute + 3*time.Second + 872*time.Millisecond, Speed: 2.093}},
 		PenaltyResult: models.PenaltyResult{Time: 1*time.Minute + 44*time.Second + 296*time.Millisecond, Speed: 0.481},
 		Hits:          4,
@@ -93,7 +99,7 @@ func captureOutput(f func()) string {
 
 // createTestProcessor creates a processor with standard test configuration
 func createTestProcessor() *EventProcessor {
-	return NewEventProcessor(config.Configuration{
+	return mustNewEventProcessor(config.Configuration{
 		Laps:        2,
 		LapLen:      3651,
 		PenaltyLen:  50,
@@ -103,16 +109,24 @@ func createTestProcessor() *EventProcessor {
 	})
 }
 
-// createTestEvent creates a standard test event
+// mustNewEventProcessor creates an EventProcessor for a configuration the test knows to be valid.
+func mustNewEventProcessor(conf config.Configuration) *EventProcessor {
+	processor, err := NewEventProcessor(conf)
+	if err != nil {
+		panic(err)
+	}
+	return processor
+}
+
+// createTestEvent creates a standard test event, parsed like a line of an events file
+// so that its typed payload is filled in
 func createTestEvent(action models.Action, competitorID int, timeStr string, extraParams string) models.Event {
 	t, _ := time.Parse(config.TimeFormat, timeStr)
-	return models.Event{
-		Time:         t,
-		TimeString:   utils.FormatTimeString(t),
-		Action:       action,
-		CompetitorID: competitorID,
-		ExtraParams:  extraParams,
+	event, err := events.ParseEvent(fmt.Sprintf("%s %d %d %s", utils.FormatTimeString(t), action, competitorID, extraParams))
+	if err != nil {
+		panic(err)
 	}
+	return event
 }
 
 func TestHandleRegistered(t *testing.T) {
@@ -225,7 +239,11 @@ func TestHandleOnFiringRange(t *testing.T) {
 
 func TestHandleHit(t *testing.T) {
 	processor := createTestProcessor()
-	comp := &models.Competitor{ID: 1, Hits: 0, LastFiringHits: 0}
+	comp := &models.Competitor{
+		ID:     1,
+		Status: models.OnFiringRange,
+		Stages: []models.ShootingStage{{Range: 1, Lap: 1, Targets: 5, TargetHits: make([]bool, 5)}},
+	}
 	event := createTestEvent(models.ActionHit, 1, "09:49:33.123", "1")
 
 	output := captureOutput(func() {
@@ -444,3 +462,363 @@ func TestHandleCannotContinue(t *testing.T) {
 		t.Errorf("Expected log to contain '%s', got: %s", expectedLog, output)
 	}
 }
+
+// sliceSource feeds events to ProcessStream from a slice.
+type sliceSource struct {
+	events []models.Event
+}
+
+func (s *sliceSource) Read() (models.Event, error) {
+	if len(s.events) == 0 {
+		return models.Event{}, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func TestProcessStream(t *testing.T) {
+	processor := createTestProcessor()
+	source := &sliceSource{events: []models.Event{
+		createTestEvent(models.ActionRegistered, 1, "09:05:59.867", ""),
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.841", "09:30:00.000"),
+		createTestEvent(models.ActionCannotContinue, 1, "09:59:05.321", "Lost in the forest"),
+	}}
+
+	var standings []string
+	captureOutput(func() {
+		err := processor.ProcessStream(source, func(models.Event) {
+			standings = append(standings, processor.Standings())
+		})
+		if err != nil {
+			t.Errorf("ProcessStream failed: %v", err)
+		}
+	})
+
+	if len(standings) != 3 {
+		t.Fatalf("Expected 3 standings updates, got %d", len(standings))
+	}
+	if !strings.HasPrefix(standings[2], "[NotFinished] 1") {
+		t.Errorf("Expected live standings to show NotFinished, got: %s", standings[2])
+	}
+	if len(processor.Events) != 3 {
+		t.Errorf("Expected 3 processed events, got %d", len(processor.Events))
+	}
+}
+
+func TestStandingsDuringRace(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Competitors[1] = &models.Competitor{ID: 1, Status: models.OnFiringRange}
+	processor.Competitors[2] = &models.Competitor{ID: 2, Status: models.Finished, TotalTime: 55 * time.Minute}
+	processor.Competitors[3] = &models.Competitor{ID: 3, Status: models.NotFinished}
+
+	standings := strings.Split(processor.Standings(), "\n")
+	expected := []string{"[00:55:00.000] 2", "[OnFiringRange] 1", "[NotFinished] 3"}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(standings[i], prefix) {
+			t.Errorf("Expected line %d to start with %q, got: %q", i, prefix, standings[i])
+		}
+	}
+}
+
+func TestProcessEventsAcrossMidnight(t *testing.T) {
+	processor := mustNewEventProcessor(config.Configuration{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  50,
+		FiringLines: 1,
+		Start:       "23:50:00.000",
+		StartDelta:  "00:00:30",
+		Date:        "2024-12-31",
+	})
+
+	events := []models.Event{
+		createTestEvent(models.ActionRegistered, 1, "23:40:00.000", ""),
+		createTestEvent(models.ActionStartTimeSet, 1, "23:45:00.000", "23:50:00.000"),
+		createTestEvent(models.ActionStarted, 1, "23:50:00.500", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "23:59:00.000", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "00:00:30.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "00:20:00.000", ""),
+	}
+
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+	})
+
+	comp := processor.Competitors[1]
+	if comp.Status != models.Finished {
+		t.Fatalf("Expected status Finished, got %v", comp.Status)
+	}
+	if comp.TotalTime != 30*time.Minute {
+		t.Errorf("Expected total time 30m across midnight, got %v", comp.TotalTime)
+	}
+	if comp.LapsResult[0].Time != 30*time.Minute {
+		t.Errorf("Expected lap time 30m across midnight, got %v", comp.LapsResult[0].Time)
+	}
+
+	expectedStart := time.Date(2024, time.December, 31, 23, 50, 0, 0, time.UTC)
+	if !comp.PlannedStart.Equal(expectedStart) {
+		t.Errorf("Expected planned start %v, got %v", expectedStart, comp.PlannedStart)
+	}
+}
+
+func TestOutgoingEvents(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.005", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:59:03.872", ""),
+		createTestEvent(models.ActionStartTimeSet, 2, "09:15:00.000", "09:31:00.000"),
+	}
+
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		processor.GenerateReport()
+		processor.GenerateReport()
+	})
+
+	var finished, disqualified int
+	for _, e := range processor.Events {
+		switch {
+		case e.Action == models.ActionFinished && e.CompetitorID == 1:
+			finished++
+		case e.Action == models.ActionDisqualified && e.CompetitorID == 2:
+			disqualified++
+			if e.TimeString != "[09:31:30.001]" {
+				t.Errorf("Expected disqualification at [09:31:30.001], got %s", e.TimeString)
+			}
+		}
+	}
+	if finished != 1 || disqualified != 1 {
+		t.Errorf("Expected one finish and one disqualification event, got %d and %d", finished, disqualified)
+	}
+}
+
+func TestReingestedOutgoingEvents(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.005", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:59:03.872", ""),
+		createTestEvent(models.ActionFinished, 1, "09:59:03.872", ""),
+		createTestEvent(models.ActionFinished, 3, "09:59:03.872", ""),
+	}
+
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+	})
+
+	if _, exists := processor.Competitors[3]; exists {
+		t.Error("Expected a re-ingested finish not to create a competitor")
+	}
+
+	var finished int
+	for _, e := range processor.Events {
+		if e.Action == models.ActionFinished && e.CompetitorID == 1 {
+			finished++
+		}
+	}
+	if finished != 1 {
+		t.Errorf("Expected the finish to be recorded once, got %d", finished)
+	}
+	if comp := processor.Competitors[1]; comp.Status != models.Finished || comp.CurrentLap != 1 {
+		t.Errorf("Expected competitor 1 to be finished once, got status %v on lap %d", comp.Status, comp.CurrentLap)
+	}
+}
+
+func TestConfiguredTimeFormat(t *testing.T) {
+	processor := mustNewEventProcessor(config.Configuration{
+		Laps:       2,
+		StartDelta: "00:00:30",
+		TimeLayout: "15:04:05,000",
+	})
+
+	processor.Competitors[1] = &models.Competitor{
+		ID:           1,
+		PlannedStart: time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC),
+		Status:       models.Registered,
+	}
+
+	var report string
+	output := captureOutput(func() {
+		report = processor.GenerateReport()
+	})
+
+	expectedLog := "[09:30:30,001] The competitor(1) is disqualified"
+	if !strings.Contains(output, expectedLog) {
+		t.Errorf("Expected log to contain '%s', got: %s", expectedLog, output)
+	}
+	if processor.Events[0].TimeString != "[09:30:30,001]" {
+		t.Errorf("Expected outgoing event time [09:30:30,001], got %s", processor.Events[0].TimeString)
+	}
+	if !strings.Contains(report, "[NotStarted] 1") {
+		t.Errorf("Unexpected report: %s", report)
+	}
+}
+
+func TestConfigurableTargets(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.FiringLines = 2
+	processor.Config.Targets = 3
+	processor.Config.Stages = []config.Stage{{Targets: 2}}
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:10.000", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:35:30.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:40:00.000", "2"),
+		createTestEvent(models.ActionHit, 1, "09:40:10.000", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:40:30.000", ""),
+		createTestEvent(models.ActionOnPenaltyLaps, 1, "09:40:40.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 1, "09:41:40.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+	}
+	captureOutput(func() { processor.ProcessEvents(events) })
+
+	comp := processor.Competitors[1]
+	if comp.Hits != 2 || comp.Shots != 5 {
+		t.Errorf("Expected 2/5 over a 2 and a 3 target stage, got %d/%d", comp.Hits, comp.Shots)
+	}
+
+	// The only visit to the penalty loops follows the second stage: 2 * 50m in a minute.
+	if speed := comp.PenaltyResult.Speed; speed != 100.0/60 {
+		t.Errorf("Expected penalty speed %.3f, got %.3f", 100.0/60, speed)
+	}
+	if speed := comp.LapsResult[0].Speed; speed != (3651.0+150)/(20*time.Minute).Seconds() {
+		t.Errorf("Expected lap speed to include 150m of penalty loops, got %.3f", speed)
+	}
+}
+
+func TestLapSpeedsUsePerLapLengths(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.FiringLines = 0
+	processor.Config.PenaltyLen = 0
+	processor.Config.LapLens = []int{3300, 2500}
+	processor.ShowDistance = true
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:40:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+	}
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	laps := processor.Competitors[1].LapsResult
+	if len(laps) != 2 || laps[0].Speed != 5.5 || laps[1].Speed != 2500.0/600 {
+		t.Errorf("Expected speeds of a 3300m and a 2500m lap, got %+v", laps)
+	}
+	// 5800m in 20 minutes
+	if !strings.Contains(report, " {5800, 4.833}") {
+		t.Errorf("Expected the total distance and average speed in the report, got: %s", report)
+	}
+}
+
+func TestPenaltyVisits(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.FiringLines = 2
+
+	stage := func(minute, firingRange string, hits int) []models.Event {
+		events := []models.Event{createTestEvent(models.ActionOnFiringRange, 1, minute+":00.000", firingRange)}
+		for target := 1; target <= hits; target++ {
+			events = append(events, createTestEvent(models.ActionHit, 1, fmt.Sprintf("%s:%02d.000", minute, 10+target), fmt.Sprint(target)))
+		}
+		return append(events, createTestEvent(models.ActionLeftFiringRange, 1, minute+":30.000", ""))
+	}
+
+	var events []models.Event
+	events = append(events, createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"))
+	events = append(events, createTestEvent(models.ActionStarted, 1, "09:30:00.000", ""))
+	events = append(events, stage("09:35", "1", 3)...)
+	events = append(events,
+		createTestEvent(models.ActionOnPenaltyLaps, 1, "09:35:40.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 1, "09:36:20.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:45:00.000", ""),
+	)
+	events = append(events, stage("09:50", "2", 4)...)
+	events = append(events, createTestEvent(models.ActionOnPenaltyLaps, 1, "09:50:40.000", ""))
+
+	captureOutput(func() { processor.ProcessEvents(events) })
+	comp := processor.Competitors[1]
+
+	// Still on the penalty loops after stage 2: the totals only count the completed visit.
+	if len(comp.PenaltyVisits) != 1 || comp.PenaltyResult.Speed != 100.0/40 {
+		t.Errorf("Expected one visit at 2.5 m/s, got %+v and %+v", comp.PenaltyVisits, comp.PenaltyResult)
+	}
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents([]models.Event{
+			createTestEvent(models.ActionLeftPenaltyLaps, 1, "09:51:00.000", ""),
+			createTestEvent(models.ActionFinishedLap, 1, "10:00:00.000", ""),
+		})
+		report = processor.GenerateReport()
+	})
+
+	expected := []models.PenaltyVisit{
+		{Stage: 1, Misses: 2, Loops: 2, Time: 40 * time.Second, Speed: 2.5},
+		{Stage: 2, Misses: 1, Loops: 1, Time: 20 * time.Second, Speed: 2.5},
+	}
+	if fmt.Sprint(comp.PenaltyVisits) != fmt.Sprint(expected) {
+		t.Errorf("Expected visits %+v, got %+v", expected, comp.PenaltyVisits)
+	}
+	if comp.PenaltyResult.Time != time.Minute || comp.PenaltyResult.Speed != 2.5 {
+		t.Errorf("Expected 1m at 2.5 m/s in total, got %+v", comp.PenaltyResult)
+	}
+	if !strings.Contains(report, "{00:01:00.000, 2.500} 7/10 [{1, 00:00:40.000, 2.500}, {2, 00:00:20.000, 2.500}]") {
+		t.Errorf("Expected total and per-stage penalty splits in the report, got: %s", report)
+	}
+}
+
+func TestTargetHits(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.FiringLines = 1
+	processor.ShowTargets = true
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.000", ""),
+		createTestEvent(models.ActionHit, 1, "09:34:00.000", "2"),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:10.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:12.000", "3"),
+		createTestEvent(models.ActionHit, 1, "09:35:14.000", "3"),
+		createTestEvent(models.ActionHit, 1, "09:35:16.000", "4"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:35:30.000", ""),
+	}
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	comp := processor.Competitors[1]
+	if comp.Hits != 3 || comp.Shots != 5 {
+		t.Errorf("Expected the duplicate and the stray hit to be refused, got %d/%d", comp.Hits, comp.Shots)
+	}
+	if pattern := comp.Stages[0].Pattern(); pattern != "x.xx." {
+		t.Errorf("Expected pattern x.xx., got %s", pattern)
+	}
+	for _, want := range []string{
+		" [x.xx.] (",
+		"target 2 hit outside a firing range visit",
+		"target 3 hit twice at stage 1",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected the report to contain %q, got: %s", want, report)
+		}
+	}
+}
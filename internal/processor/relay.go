@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"yadro-biathlon/internal/messages"
+	"yadro-biathlon/internal/models"
+)
+
+// setupTeams creates the configured relay teams. The first leg starts with the relay.
+func (ep *EventProcessor) setupTeams() {
+	for _, spec := range ep.Config.Teams {
+		team := &models.Team{ID: spec.ID}
+		for _, id := range spec.Members {
+			team.Legs = append(team.Legs, models.Leg{CompetitorID: id})
+		}
+		if len(team.Legs) > 0 {
+			team.Legs[0].Start, _ = ep.rules.AssignedStart(team.Legs[0].CompetitorID)
+		}
+		ep.Teams[team.ID] = team
+	}
+}
+
+// teamOf returns the relay team of a competitor and the index of their leg, or nil.
+func (ep *EventProcessor) teamOf(competitorID int) (*models.Team, int) {
+	for _, team := range ep.Teams {
+		if leg := team.Leg(competitorID); leg >= 0 {
+			return team, leg
+		}
+	}
+	return nil, -1
+}
+
+func (ep *EventProcessor) handleSpareRound(event models.Event, comp *models.Competitor) {
+	ep.WriteLog(fmt.Sprintf(messages.SpareRound, event.TimeString, comp.ID))
+	if len(comp.Stages) == 0 {
+		ep.flag(comp, event, messages.IrregularShooting, "spare round loaded off the firing range")
+		return
+	}
+
+	stage := &comp.Stages[len(comp.Stages)-1]
+	stage.Spares++
+	if spares := stage.Rounds - stage.Targets; stage.Spares == spares+1 {
+		ep.flag(comp, event, messages.IrregularShooting,
+			fmt.Sprintf("more than %d spare rounds at stage %d", spares, len(comp.Stages)))
+	}
+}
+
+// handleExchange starts the next leg of a relay: the incoming athlete's race starts at the exchange.
+func (ep *EventProcessor) handleExchange(event models.Event, comp *models.Competitor) {
+	payload, _ := event.Payload.(models.ExchangePayload)
+	ep.WriteLog(fmt.Sprintf(messages.Exchange, event.TimeString, comp.ID, payload.Next))
+
+	team, leg := ep.teamOf(comp.ID)
+	switch {
+	case team == nil:
+		ep.flag(comp, event, messages.IrregularExchange, "not in a relay team")
+	case leg == len(team.Legs)-1:
+		ep.flag(comp, event, messages.IrregularExchange, "the last leg has nobody to hand over to")
+	case team.Legs[leg+1].CompetitorID != payload.Next:
+		ep.flag(comp, event, messages.IrregularExchange, fmt.Sprintf("handed over to competitor(%d) instead of competitor(%d)",
+			payload.Next, team.Legs[leg+1].CompetitorID))
+	}
+	if comp.Status != models.Finished {
+		ep.flag(comp, event, messages.IrregularExchange, "handed over before finishing the leg")
+	}
+
+	next := ep.competitor(payload.Next)
+	next.PlannedStart = event.Time
+	next.ActualStart = event.Time
+	next.LapStartTime = event.Time
+	next.CurrentLap = 1
+	next.Status = models.Started
+	if team, leg := ep.teamOf(next.ID); team != nil {
+		team.Legs[leg].Start = event.Time
+	}
+}
+
+// finishLeg records the finish of a relay athlete's leg.
+func (ep *EventProcessor) finishLeg(comp *models.Competitor, t time.Time) {
+	if team, leg := ep.teamOf(comp.ID); team != nil {
+		team.Legs[leg].Finish = t
+	}
+}
+
+// teamStandings returns the relay results: finished teams by total time, then, by ID, those
+// that did not finish, those disqualified and those that did not start.
+// Each line lists the legs as {competitor, leg time, hits/shots, penalty loops}.
+func (ep *EventProcessor) teamStandings() string {
+	var teams []*models.Team
+	for _, team := range ep.Teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		a, b := teams[i], teams[j]
+		if groupA, groupB := standingsGroup(ep.teamStatus(a)), standingsGroup(ep.teamStatus(b)); groupA != groupB {
+			return groupA < groupB
+		}
+		if a.Finished() && a.TotalTime() != b.TotalTime() {
+			return a.TotalTime() < b.TotalTime()
+		}
+		return a.ID < b.ID
+	})
+
+	var report strings.Builder
+	for _, team := range teams {
+		if status := ep.teamStatus(team); status == models.Finished {
+			report.WriteString(fmt.Sprintf("[%s] team %d", ep.clock.FormatDurationString(team.TotalTime()), team.ID))
+		} else {
+			report.WriteString(fmt.Sprintf("[%s] team %d", status, team.ID))
+		}
+
+		report.WriteString(" [")
+		for i, leg := range team.Legs {
+			if i > 0 {
+				report.WriteString(", ")
+			}
+			comp, ok := ep.Competitors[leg.CompetitorID]
+			if !ok || leg.Finish.IsZero() {
+				report.WriteString(fmt.Sprintf("{%d,,,}", leg.CompetitorID))
+				continue
+			}
+			report.WriteString(fmt.Sprintf("{%d, %s, %d/%d, %d}", comp.ID,
+				ep.clock.FormatDurationString(leg.Finish.Sub(leg.Start)), comp.Hits, comp.Shots, ep.penaltyLoops(comp)))
+		}
+		report.WriteString("]\n")
+	}
+	return report.String()
+}
+
+// teamStatus returns the status a team is listed with: Disqualified if one of its athletes was,
+// Finished once every leg has finished, NotStarted before the first leg starts and NotFinished otherwise.
+func (ep *EventProcessor) teamStatus(team *models.Team) models.CompetitorStatus {
+	for _, leg := range team.Legs {
+		if ep.legStatus(leg) == models.Disqualified {
+			return models.Disqualified
+		}
+	}
+	switch {
+	case team.Finished():
+		return models.Finished
+	case len(team.Legs) == 0 || ep.legStatus(team.Legs[0]) == models.NotStarted:
+		return models.NotStarted
+	}
+	return models.NotFinished
+}
+
+func (ep *EventProcessor) legStatus(leg models.Leg) models.CompetitorStatus {
+	if comp, ok := ep.Competitors[leg.CompetitorID]; ok {
+		return comp.Status
+	}
+	return models.NotStarted
+}
+
+// penaltyLoops returns the number of penalty loops the competitor owed over all stages.
+func (ep *EventProcessor) penaltyLoops(comp *models.Competitor) int {
+	loops := 0
+	for _, stage := range comp.Stages {
+		loops += ep.rules.PenaltyLoops(stage.Misses())
+	}
+	return loops
+}
+
+// spareRounds returns the number of spare rounds the competitor loaded over all stages.
+func spareRounds(comp *models.Competitor) int {
+	spares := 0
+	for _, stage := range comp.Stages {
+		spares += stage.Spares
+	}
+	return spares
+}
@@ -0,0 +1,158 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+func createRelayProcessor() *EventProcessor {
+	conf := createTestProcessor().Config
+	conf.Laps = 1
+	conf.Format = config.FormatRelay
+	conf.Teams = []config.Team{{ID: 1, Members: []int{11, 12}}, {ID: 2, Members: []int{21, 22}}}
+	return mustNewEventProcessor(conf)
+}
+
+// cleanStage returns the events of a firing range visit with every target hit.
+func cleanStage(competitorID int, minute string) []models.Event {
+	events := []models.Event{createTestEvent(models.ActionOnFiringRange, competitorID, minute+":00.000", "1")}
+	for target := 1; target <= config.DefaultTargets; target++ {
+		events = append(events, createTestEvent(models.ActionHit, competitorID, fmt.Sprintf("%s:%02d.000", minute, 10+target), fmt.Sprint(target)))
+	}
+	return append(events, createTestEvent(models.ActionLeftFiringRange, competitorID, minute+":30.000", ""))
+}
+
+func TestRelay(t *testing.T) {
+	processor := createRelayProcessor()
+
+	var events []models.Event
+	events = append(events,
+		createTestEvent(models.ActionStarted, 11, "09:30:00.100", ""),
+		createTestEvent(models.ActionStarted, 21, "09:30:00.200", ""),
+		createTestEvent(models.ActionOnFiringRange, 11, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 11, "09:35:10.000", "1"),
+		createTestEvent(models.ActionHit, 11, "09:35:12.000", "2"),
+		createTestEvent(models.ActionHit, 11, "09:35:14.000", "3"),
+		createTestEvent(models.ActionSpareRound, 11, "09:35:20.000", ""),
+		createTestEvent(models.ActionHit, 11, "09:35:25.000", "4"),
+		createTestEvent(models.ActionSpareRound, 11, "09:35:30.000", ""),
+		createTestEvent(models.ActionLeftFiringRange, 11, "09:36:00.000", ""),
+		createTestEvent(models.ActionOnPenaltyLaps, 11, "09:36:10.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 11, "09:36:40.000", ""),
+	)
+	events = append(events, cleanStage(21, "09:37")...)
+	events = append(events,
+		createTestEvent(models.ActionFinishedLap, 21, "09:44:00.000", ""),
+		createTestEvent(models.ActionExchange, 21, "09:44:00.000", "22"),
+		createTestEvent(models.ActionFinishedLap, 11, "09:45:00.000", ""),
+		createTestEvent(models.ActionExchange, 11, "09:45:00.000", "12"),
+	)
+	events = append(events, cleanStage(12, "09:50")...)
+	events = append(events,
+		createTestEvent(models.ActionCannotContinue, 22, "09:52:00.000", "Lost in the forest"),
+		createTestEvent(models.ActionFinishedLap, 12, "10:00:00.000", ""),
+	)
+
+	var report string
+	output := captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	if strings.Contains(output, "irregular") || strings.Contains(output, "disqualified") {
+		t.Errorf("Expected a regular relay, got: %s", output)
+	}
+	if !strings.Contains(output, "[09:45:00.000] The competitor(11) handed over to the competitor(12)") {
+		t.Errorf("Expected the exchange to be logged, got: %s", output)
+	}
+	if comp := processor.Competitors[11]; comp.Shots != 7 || comp.Hits != 4 || !strings.Contains(report, "] {00:00:30.000, 1.667} 4/7 [{1, 00:00:30.000, 1.667}]\n") {
+		t.Errorf("Expected one penalty loop after two spare rounds, got %d/%d in:\n%s", comp.Hits, comp.Shots, report)
+	}
+
+	teams := report[strings.Index(report, "\n\n")+2:]
+	expected := "[00:30:00.000] team 1 [{11, 00:15:00.000, 4/7, 1}, {12, 00:15:00.000, 5/5, 0}]\n" +
+		"[NotFinished] team 2 [{21, 00:14:00.000, 5/5, 0}, {22,,,}]\n"
+	if teams != expected {
+		t.Errorf("Expected team results:\n%s\ngot:\n%s", expected, teams)
+	}
+}
+
+func TestRelayIrregularities(t *testing.T) {
+	processor := createRelayProcessor()
+
+	events := []models.Event{
+		createTestEvent(models.ActionStarted, 11, "09:30:00.100", ""),
+		createTestEvent(models.ActionOnFiringRange, 11, "09:35:00.000", "1"),
+	}
+	for i := 0; i < 4; i++ {
+		events = append(events, createTestEvent(models.ActionSpareRound, 11, fmt.Sprintf("09:35:%02d.000", 20+i), ""))
+	}
+	events = append(events,
+		createTestEvent(models.ActionLeftFiringRange, 11, "09:36:00.000", ""),
+		createTestEvent(models.ActionExchange, 11, "09:40:00.000", "22"),
+	)
+
+	output := captureOutput(func() { processor.ProcessEvents(events) })
+
+	for _, issue := range []string{
+		"more than 3 spare rounds at stage 1",
+		"handed over to competitor(22) instead of competitor(12)",
+		"handed over before finishing the leg",
+	} {
+		if !strings.Contains(output, issue) {
+			t.Errorf("Expected %q to be logged, got: %s", issue, output)
+		}
+	}
+	if processor.Competitors[22].Status != models.Started {
+		t.Errorf("Expected competitor 22 to start at the exchange anyway, got %v", processor.Competitors[22].Status)
+	}
+}
+
+func TestRelayTeamsWithoutResult(t *testing.T) {
+	processor := createRelayProcessor()
+	processor.Config.SkippedLoops = config.SkippedLoopsDisqualify
+
+	events := []models.Event{
+		createTestEvent(models.ActionStarted, 11, "09:30:00.100", ""),
+		createTestEvent(models.ActionStarted, 21, "09:30:00.200", ""),
+		createTestEvent(models.ActionOnFiringRange, 11, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 11, "09:35:10.000", "1"),
+		createTestEvent(models.ActionHit, 11, "09:35:12.000", "2"),
+		createTestEvent(models.ActionHit, 11, "09:35:14.000", "3"),
+		createTestEvent(models.ActionLeftFiringRange, 11, "09:36:00.000", ""),
+		createTestEvent(models.ActionOnPenaltyLaps, 11, "09:36:10.000", ""),
+		createTestEvent(models.ActionPenaltyLoop, 11, "09:36:40.000", ""),
+		createTestEvent(models.ActionLeftPenaltyLaps, 11, "09:36:40.000", ""),
+	}
+	events = append(events, cleanStage(21, "09:37")...)
+	events = append(events,
+		createTestEvent(models.ActionExchange, 21, "09:44:00.000", "22"),
+		createTestEvent(models.ActionFinishedLap, 11, "09:45:00.000", ""),
+		createTestEvent(models.ActionExchange, 11, "09:45:00.000", "12"),
+	)
+	events = append(events, cleanStage(12, "09:50")...)
+	events = append(events, cleanStage(22, "09:50")...)
+	events = append(events,
+		createTestEvent(models.ActionFinishedLap, 22, "09:58:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 12, "10:00:00.000", ""),
+	)
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	if processor.Teams[1].Finished() || processor.Teams[2].Finished() {
+		t.Errorf("Expected neither team to finish, got %+v and %+v", processor.Teams[1], processor.Teams[2])
+	}
+	teams := report[strings.Index(report, "\n\n")+2:]
+	expected := "[NotFinished] team 2 [{21,,,}, {22, 00:14:00.000, 5/5, 0}]\n" +
+		"[Disqualified] team 1 [{11,,,}, {12, 00:15:00.000, 5/5, 0}]\n"
+	if !strings.HasPrefix(teams, expected) {
+		t.Errorf("Expected team results:\n%s\ngot:\n%s", expected, teams)
+	}
+}
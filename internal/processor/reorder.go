@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"yadro-biathlon/internal/models"
+)
+
+// reorderBuffer holds events back for a lateness window so that events which
+// arrive slightly out of time order are released to the handlers in order.
+type reorderBuffer struct {
+	window      time.Duration
+	pending     []models.Event
+	latest      time.Time
+	hasLatest   bool
+	released    time.Time
+	hasReleased bool
+}
+
+func newReorderBuffer(window time.Duration) *reorderBuffer {
+	return &reorderBuffer{window: window}
+}
+
+// push adds an event and returns the events that are now older than the newest
+// timestamp minus the window, in time order. An event older than one that was
+// already released cannot be put back in order and is rejected with an error.
+func (b *reorderBuffer) push(event models.Event) ([]models.Event, error) {
+	if b.hasReleased && event.Time.Before(b.released) {
+		return nil, fmt.Errorf("%s behind the latest event, outside the reorder window of %s",
+			b.latest.Sub(event.Time), b.window)
+	}
+
+	// Insert after events with the same time to keep arrival order for ties.
+	i := sort.Search(len(b.pending), func(i int) bool {
+		return b.pending[i].Time.After(event.Time)
+	})
+	b.pending = append(b.pending, models.Event{})
+	copy(b.pending[i+1:], b.pending[i:])
+	b.pending[i] = event
+
+	if !b.hasLatest || event.Time.After(b.latest) {
+		b.latest = event.Time
+		b.hasLatest = true
+	}
+
+	deadline := b.latest.Add(-b.window)
+	n := 0
+	for n < len(b.pending) && !b.pending[n].Time.After(deadline) {
+		n++
+	}
+	return b.release(n), nil
+}
+
+// flush releases every pending event, e.g. once the input has ended.
+func (b *reorderBuffer) flush() []models.Event {
+	return b.release(len(b.pending))
+}
+
+func (b *reorderBuffer) release(n int) []models.Event {
+	if n == 0 {
+		return nil
+	}
+
+	ready := make([]models.Event, n)
+	copy(ready, b.pending[:n])
+	b.pending = b.pending[n:]
+	b.released = ready[n-1].Time
+	b.hasReleased = true
+	return ready
+}
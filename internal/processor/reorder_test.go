@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"yadro-biathlon/internal/models"
+)
+
+func TestReorderBuffer(t *testing.T) {
+	buffer := newReorderBuffer(time.Second)
+
+	hit := createTestEvent(models.ActionHit, 1, "10:08:55.900", "5")
+	left := createTestEvent(models.ActionLeftFiringRange, 1, "10:08:55.658", "")
+	penalty := createTestEvent(models.ActionOnPenaltyLaps, 1, "10:09:03.232", "")
+
+	for _, event := range []models.Event{left, hit} {
+		ready, err := buffer.push(event)
+		if err != nil {
+			t.Fatalf("push failed: %v", err)
+		}
+		if len(ready) != 0 {
+			t.Errorf("Expected events to be held within the window, got %d released", len(ready))
+		}
+	}
+
+	ready, err := buffer.push(penalty)
+	if err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if len(ready) != 2 || ready[0].Action != models.ActionLeftFiringRange || ready[1].Action != models.ActionHit {
+		t.Errorf("Expected left firing range then hit in time order, got %+v", ready)
+	}
+
+	late := createTestEvent(models.ActionHit, 1, "10:08:50.000", "1")
+	if _, err := buffer.push(late); err == nil {
+		t.Error("Expected event older than the released ones to be rejected")
+	}
+
+	rest := buffer.flush()
+	if len(rest) != 1 || rest[0].Action != models.ActionOnPenaltyLaps {
+		t.Errorf("Expected flush to release the penalty event, got %+v", rest)
+	}
+}
+
+func TestProcessEventsReordered(t *testing.T) {
+	processor := createTestProcessor()
+	processor.reorder = newReorderBuffer(time.Second)
+
+	events := []models.Event{
+		createTestEvent(models.ActionOnFiringRange, 1, "10:08:49.289", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "10:08:55.658", ""),
+		createTestEvent(models.ActionHit, 1, "10:08:55.300", "1"),
+		createTestEvent(models.ActionOnPenaltyLaps, 1, "10:09:03.232", ""),
+		createTestEvent(models.ActionHit, 1, "10:08:55.500", "2"),
+	}
+
+	output := captureOutput(func() {
+		processor.ProcessEvents(events)
+	})
+
+	comp := processor.Competitors[1]
+	if comp.Hits != 1 {
+		t.Errorf("Expected only the reordered hit to count, got %d hits", comp.Hits)
+	}
+	if processor.Events[2].Action != models.ActionLeftFiringRange {
+		t.Errorf("Expected the hit to be processed before leaving the range, got %v", processor.Events[2].Action)
+	}
+
+	expectedLog := "The event(6) of competitor(1) was rejected"
+	if !strings.Contains(output, expectedLog) {
+		t.Errorf("Expected log to contain '%s', got: %s", expectedLog, output)
+	}
+}
+
+func TestSubmitDetectsBackwardsTime(t *testing.T) {
+	processor := createTestProcessor()
+
+	output := captureOutput(func() {
+		processor.Submit(createTestEvent(models.ActionLeftFiringRange, 1, "10:08:55.658", ""))
+		processor.Submit(createTestEvent(models.ActionHit, 1, "10:08:55.300", "1"))
+	})
+
+	if len(processor.Events) != 2 || processor.Events[1].Action != models.ActionHit {
+		t.Errorf("Expected the event to still be processed without a reorder window")
+	}
+
+	expectedLog := "The event(6) of competitor(1) is 358ms older than the latest event"
+	if !strings.Contains(output, expectedLog) {
+		t.Errorf("Expected log to contain '%s', got: %s", expectedLog, output)
+	}
+}
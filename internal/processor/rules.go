@@ -0,0 +1,304 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+	"yadro-biathlon/internal/utils"
+)
+
+// Rules is the rule set of a race format. EventProcessor asks it wherever formats differ,
+// so the handlers stay the same for every format.
+type Rules interface {
+	startRule
+	penaltyRule
+	ranking
+
+	// Position returns the shooting position of a stage, numbered from 0.
+	Position(stage int) models.Position
+}
+
+// startRule decides when a competitor's race time starts and who missed the start.
+type startRule interface {
+	// AssignedStart returns the start time the format gives a competitor instead of the draw, if any.
+	AssignedStart(id int) (time.Time, bool)
+	// RaceStart returns the moment the competitor's race time is counted from.
+	RaceStart(comp *models.Competitor) time.Time
+	// Disqualified reports whether the competitor missed the start and when the disqualification takes effect.
+	Disqualified(comp *models.Competitor) (time.Time, bool)
+}
+
+// penaltyRule decides what missed targets cost.
+type penaltyRule interface {
+	// PenaltyLoops returns the number of penalty loops owed for misses.
+	PenaltyLoops(misses int) int
+	// TimePenalty returns the time added to the result for misses.
+	TimePenalty(misses int) time.Duration
+	// Timed reports whether misses cost time rather than penalty loops.
+	Timed() bool
+}
+
+// ranking orders the finishers.
+type ranking interface {
+	// Less reports whether finisher a ranks ahead of finisher b.
+	Less(a, b *models.Competitor) bool
+}
+
+// NewRules returns the rule set of the configured race format.
+func NewRules(conf config.Configuration) (Rules, error) {
+	delta, err := conf.StartDeltaDuration()
+	if err != nil {
+		return nil, fmt.Errorf("start delta: %w", err)
+	}
+
+	switch conf.RaceFormat() {
+	case config.FormatSprint:
+		return preset{
+			startRule:     intervalStart{delta: delta},
+			penaltyRule:   penaltyLoops{},
+			shootingOrder: shootingOrder{models.Prone, models.Standing},
+			ranking:       byTime{},
+		}, nil
+	case config.FormatIndividual:
+		perMiss, err := conf.MissPenaltyDuration()
+		if err != nil {
+			return nil, fmt.Errorf("miss penalty: %w", err)
+		}
+		return preset{
+			startRule:     intervalStart{delta: delta},
+			penaltyRule:   timePenalty{perMiss: perMiss},
+			shootingOrder: shootingOrder{models.Prone, models.Standing, models.Prone, models.Standing},
+			ranking:       byTime{},
+		}, nil
+	case config.FormatPursuit:
+		start, err := commonStart(conf)
+		if err != nil {
+			return nil, err
+		}
+		gaps, err := handicapGaps(conf)
+		if err != nil {
+			return nil, err
+		}
+		return preset{
+			startRule:     handicapStart{start: start, gaps: gaps, delta: delta},
+			penaltyRule:   penaltyLoops{},
+			shootingOrder: shootingOrder{models.Prone, models.Prone, models.Standing, models.Standing},
+			ranking:       byFinish{},
+		}, nil
+	case config.FormatMassStart:
+		start, err := commonStart(conf)
+		if err != nil {
+			return nil, err
+		}
+		return preset{
+			startRule:     massStart{start: start, delta: delta},
+			penaltyRule:   penaltyLoops{},
+			shootingOrder: shootingOrder{models.Prone, models.Prone, models.Standing, models.Standing},
+			ranking:       byFinish{},
+		}, nil
+	case config.FormatRelay:
+		start, err := commonStart(conf)
+		if err != nil {
+			return nil, err
+		}
+		firstLegs := make(map[int]bool, len(conf.Teams))
+		for _, team := range conf.Teams {
+			if len(team.Members) > 0 {
+				firstLegs[team.Members[0]] = true
+			}
+		}
+		return preset{
+			startRule:     relayStart{start: start, firstLegs: firstLegs, delta: delta},
+			penaltyRule:   penaltyLoops{},
+			shootingOrder: shootingOrder{models.Prone, models.Standing},
+			ranking:       byTime{},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown race format %q", conf.Format)
+}
+
+// commonStart returns the configured start time on the race date.
+func commonStart(conf config.Configuration) (time.Time, error) {
+	start, err := conf.StartTime()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("start: %w", err)
+	}
+	date, err := conf.RaceDate()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date: %w", err)
+	}
+	return utils.OnDay(start, date), nil
+}
+
+// handicapGaps loads the start gaps of a pursuit from the configured prior results.
+// Without prior results every competitor keeps the start time of the draw.
+func handicapGaps(conf config.Configuration) (map[int]time.Duration, error) {
+	if conf.PriorResults == "" {
+		return nil, nil
+	}
+
+	var limit time.Duration
+	if conf.HandicapCap != "" {
+		var err error
+		if limit, err = config.ParseDuration(conf.HandicapCap); err != nil {
+			return nil, fmt.Errorf("handicap cap: %w", err)
+		}
+	}
+
+	results, err := LoadResults(conf.PriorResults)
+	if err != nil {
+		return nil, fmt.Errorf("prior results: %w", err)
+	}
+	return Handicaps(results, limit), nil
+}
+
+// preset puts a race format together from its parts.
+type preset struct {
+	startRule
+	penaltyRule
+	shootingOrder
+	ranking
+}
+
+// intervalStart sends competitors off one by one at their drawn start time.
+// Starting before it or more than delta after it disqualifies.
+type intervalStart struct {
+	delta time.Duration
+}
+
+func (s intervalStart) AssignedStart(int) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (s intervalStart) RaceStart(comp *models.Competitor) time.Time {
+	return comp.PlannedStart
+}
+
+func (s intervalStart) Disqualified(comp *models.Competitor) (time.Time, bool) {
+	endStartInterval := comp.PlannedStart.Add(s.delta)
+	late := comp.ActualStart.IsZero() || comp.ActualStart.After(endStartInterval) || comp.ActualStart.Before(comp.PlannedStart)
+	return endStartInterval.Add(time.Millisecond), late
+}
+
+// handicapStart sends competitors off after the common start by their gap behind the winner
+// of an earlier race. Competitors without a gap keep the start time of the draw.
+// There is no start window: only competitors who never start are disqualified.
+type handicapStart struct {
+	start time.Time
+	gaps  map[int]time.Duration
+	delta time.Duration
+}
+
+func (s handicapStart) AssignedStart(id int) (time.Time, bool) {
+	gap, ok := s.gaps[id]
+	return s.start.Add(gap), ok
+}
+
+func (s handicapStart) RaceStart(comp *models.Competitor) time.Time {
+	return comp.PlannedStart
+}
+
+func (s handicapStart) Disqualified(comp *models.Competitor) (time.Time, bool) {
+	return comp.PlannedStart.Add(s.delta).Add(time.Millisecond), comp.ActualStart.IsZero()
+}
+
+// massStart sends all competitors off together at the configured start time, so a drawn
+// start time is not needed, and results are gun times counted from it. There is no start
+// window: only competitors who never start are disqualified.
+type massStart struct {
+	start time.Time
+	delta time.Duration
+}
+
+func (s massStart) AssignedStart(int) (time.Time, bool) {
+	return s.start, true
+}
+
+func (s massStart) RaceStart(*models.Competitor) time.Time {
+	return s.start
+}
+
+func (s massStart) Disqualified(comp *models.Competitor) (time.Time, bool) {
+	return s.start.Add(s.delta).Add(time.Millisecond), comp.ActualStart.IsZero()
+}
+
+// relayStart sends the first leg of every team off together at the configured start time.
+// The other legs start when their teammate hands over, so only the first legs have an assigned start.
+// Competitors who never start are disqualified.
+type relayStart struct {
+	start     time.Time
+	firstLegs map[int]bool
+	delta     time.Duration
+}
+
+func (s relayStart) AssignedStart(id int) (time.Time, bool) {
+	return s.start, s.firstLegs[id]
+}
+
+func (s relayStart) RaceStart(comp *models.Competitor) time.Time {
+	return comp.PlannedStart
+}
+
+func (s relayStart) Disqualified(comp *models.Competitor) (time.Time, bool) {
+	return s.start.Add(s.delta).Add(time.Millisecond), comp.ActualStart.IsZero()
+}
+
+// penaltyLoops makes competitors ski one penalty loop per target left standing.
+type penaltyLoops struct{}
+
+func (penaltyLoops) PenaltyLoops(misses int) int {
+	return misses
+}
+
+func (penaltyLoops) TimePenalty(int) time.Duration {
+	return 0
+}
+
+func (penaltyLoops) Timed() bool {
+	return false
+}
+
+// timePenalty adds a fixed time per miss to the result instead of penalty loops.
+type timePenalty struct {
+	perMiss time.Duration
+}
+
+func (p timePenalty) PenaltyLoops(int) int {
+	return 0
+}
+
+func (p timePenalty) TimePenalty(misses int) time.Duration {
+	return time.Duration(misses) * p.perMiss
+}
+
+func (p timePenalty) Timed() bool {
+	return true
+}
+
+// shootingOrder lists the positions of the stages; races with more stages repeat it.
+type shootingOrder []models.Position
+
+func (o shootingOrder) Position(stage int) models.Position {
+	return o[stage%len(o)]
+}
+
+// byTime ranks finishers by their total time, time penalties included.
+type byTime struct{}
+
+func (byTime) Less(a, b *models.Competitor) bool {
+	if a.TotalTime == b.TotalTime {
+		return a.ID < b.ID
+	}
+	return a.TotalTime < b.TotalTime
+}
+
+// byFinish ranks finishers in the order they crossed the finish line.
+type byFinish struct{}
+
+func (byFinish) Less(a, b *models.Competitor) bool {
+	if a.FinishTime.Equal(b.FinishTime) {
+		return a.ID < b.ID
+	}
+	return a.FinishTime.Before(b.FinishTime)
+}
@@ -0,0 +1,224 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"yadro-biathlon/internal/config"
+	"yadro-biathlon/internal/models"
+)
+
+func TestNewRules(t *testing.T) {
+	conf := createTestProcessor().Config
+	for _, format := range config.Formats {
+		conf.Format = format
+		if _, err := NewRules(conf); err != nil {
+			t.Errorf("Expected rules for %s, got: %v", format, err)
+		}
+	}
+
+	conf.Format = "relay race"
+	if _, err := NewRules(conf); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestShootingOrder(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{config.FormatSprint, "prone standing prone standing"},
+		{config.FormatIndividual, "prone standing prone standing"},
+		{config.FormatPursuit, "prone prone standing standing"},
+		{config.FormatMassStart, "prone prone standing standing"},
+	}
+
+	conf := createTestProcessor().Config
+	for _, test := range tests {
+		conf.Format = test.format
+		rules, _ := NewRules(conf)
+
+		var positions []string
+		for stage := 0; stage < 4; stage++ {
+			positions = append(positions, rules.Position(stage).String())
+		}
+		if got := strings.Join(positions, " "); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.format, test.expected, got)
+		}
+	}
+}
+
+func TestIndividualAddsTimePenalty(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.Format = config.FormatIndividual
+	processor.rules, _ = NewRules(processor.Config)
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.005", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:40:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:40:10.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:40:12.000", "2"),
+		createTestEvent(models.ActionHit, 1, "09:40:14.000", "3"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:40:30.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+	}
+	captureOutput(func() { processor.ProcessEvents(events) })
+
+	comp := processor.Competitors[1]
+	if comp.TimePenalty != 2*time.Minute {
+		t.Errorf("Expected a 2 minute penalty, got %v", comp.TimePenalty)
+	}
+	if comp.TotalTime != 22*time.Minute {
+		t.Errorf("Expected total time 22m with the penalty, got %v", comp.TotalTime)
+	}
+	if comp.Stages[0].Hits != 3 || comp.Stages[0].Position != models.Prone {
+		t.Errorf("Expected a prone stage with 3 hits, got %+v", comp.Stages[0])
+	}
+	if speed := comp.LapsResult[0].Speed; speed != float64(processor.Config.LapLen)/(20*time.Minute).Seconds() {
+		t.Errorf("Expected lap speed without penalty loops, got %v", speed)
+	}
+}
+
+func TestMassStartCountsFromCommonStart(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.Format = config.FormatMassStart
+	processor.rules, _ = NewRules(processor.Config)
+
+	events := []models.Event{
+		createTestEvent(models.ActionRegistered, 1, "09:05:00.000", ""),
+		createTestEvent(models.ActionRegistered, 2, "09:06:00.000", ""),
+		createTestEvent(models.ActionStarted, 1, "09:30:02.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+	}
+
+	var output string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		output = captureOutput(processor.CheckDisqualifications)
+	})
+
+	if comp := processor.Competitors[1]; comp.TotalTime != 20*time.Minute || comp.Status != models.Finished {
+		t.Errorf("Expected competitor 1 to finish in 20m, got %v (%v)", comp.TotalTime, comp.Status)
+	}
+	if processor.Competitors[2].Status != models.NotStarted {
+		t.Errorf("Expected competitor 2 to be disqualified, got %v", processor.Competitors[2].Status)
+	}
+	if !strings.Contains(output, "[09:30:30.001] The competitor(2) is disqualified") {
+		t.Errorf("Expected disqualification at the end of the start window, got: %s", output)
+	}
+}
+
+func TestPursuitDisqualifiesOnlyNoShows(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Format = config.FormatPursuit
+	processor.rules, _ = NewRules(processor.Config)
+
+	planned := time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC)
+	processor.Competitors[1] = &models.Competitor{ID: 1, PlannedStart: planned, ActualStart: planned.Add(2 * time.Minute)}
+	processor.Competitors[2] = &models.Competitor{ID: 2, PlannedStart: planned}
+
+	captureOutput(processor.CheckDisqualifications)
+
+	if processor.Competitors[1].Status == models.NotStarted {
+		t.Error("Expected a late start not to disqualify in a pursuit")
+	}
+	if processor.Competitors[2].Status != models.NotStarted {
+		t.Errorf("Expected a no-show to be disqualified, got %v", processor.Competitors[2].Status)
+	}
+}
+
+func TestIndividualRanksByAdjustedTime(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.Format = config.FormatIndividual
+	processor.Config.MissPenalty = "00:00:45"
+	processor.rules, _ = NewRules(processor.Config)
+
+	// Competitor 1 is faster on skis but misses four targets, competitor 2 misses none.
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStartTimeSet, 2, "09:15:01.000", "09:31:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:00.500", ""),
+		createTestEvent(models.ActionStarted, 2, "09:31:00.500", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:40:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:40:10.000", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:40:30.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 2, "09:41:00.000", "1"),
+	}
+	for target := 1; target <= config.DefaultTargets; target++ {
+		events = append(events, createTestEvent(models.ActionHit, 2, fmt.Sprintf("09:41:%02d.000", 10+target), fmt.Sprint(target)))
+	}
+	events = append(events,
+		createTestEvent(models.ActionLeftFiringRange, 2, "09:41:30.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 2, "09:52:00.000", ""),
+	)
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two report lines, got: %s", report)
+	}
+	if !strings.HasPrefix(lines[0], "[00:21:00.000] 2 ") || !strings.Contains(lines[0], " +00:00:00.000 5/5") {
+		t.Errorf("Expected competitor 2 first without penalty, got: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[00:23:00.000] 1 ") || !strings.Contains(lines[1], " {,} +00:03:00.000 1/5") {
+		t.Errorf("Expected competitor 1 second with a 3 minute penalty, got: %s", lines[1])
+	}
+}
+
+func TestMassStartIgnoresDrawAndRanksByFinish(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 1
+	processor.Config.Format = config.FormatMassStart
+	processor.rules, _ = NewRules(processor.Config)
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:45:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:00.200", ""),
+		createTestEvent(models.ActionStarted, 2, "09:33:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 2, "09:50:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:50:00.000", ""),
+		createTestEvent(models.ActionFinishedLap, 3, "09:49:00.000", ""),
+	}
+
+	var report string
+	output := captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	if !strings.Contains(output, "The drawn start time 09:45:00.000 of the competitor(1) is ignored, the race format starts them at 09:30:00.000") {
+		t.Errorf("Expected the draw to be ignored, got: %s", output)
+	}
+	if strings.Contains(output, "The competitor(2) is disqualified") {
+		t.Errorf("Expected a late starter not to be disqualified, got: %s", output)
+	}
+
+	// Competitor 3 finishing a lap without starting is reported as an anomaly after the standings.
+	standings, anomalies, _ := strings.Cut(report, "\nAnomalies:\n")
+	if !strings.Contains(anomalies, "event(10) of competitor(3) in state Registered") {
+		t.Errorf("Expected an anomaly for competitor 3, got: %s", anomalies)
+	}
+
+	lines := strings.Split(strings.TrimSpace(standings), "\n")
+	expected := []string{"[00:20:00.000] 1 ", "[00:20:00.000] 2 ", "[NotStarted] 3 "}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d report lines, got:\n%s", len(expected), report)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Expected line %d to start with %q, got: %s", i+1, prefix, lines[i])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"yadro-biathlon/internal/models"
+)
+
+// shootingReport returns the Shooting section of the final report, one line per competitor
+// with firing range visits in the order of the standings:
+//
+//	id hits-per-stage [{range, lap, position, arrival, departure, targets}, ...]
+//
+// e.g. "1 4-5 [{1, 1, prone, 09:49:31.659, 09:49:58.000, xx.xx}, ...]". The departure is
+// empty while the competitor is still on the range.
+func (ep *EventProcessor) shootingReport() string {
+	var report strings.Builder
+	report.WriteString("\nShooting:\n")
+	for _, comp := range ep.ranked() {
+		if len(comp.Stages) == 0 {
+			continue
+		}
+
+		hits := make([]string, len(comp.Stages))
+		stages := make([]string, len(comp.Stages))
+		for i, stage := range comp.Stages {
+			hits[i] = strconv.Itoa(stage.Hits)
+			stages[i] = fmt.Sprintf("{%d, %d, %s, %s, %s, %s}", stage.Range, stage.Lap, stage.Position,
+				ep.clock.Format(stage.Arrival), ep.formatDeparture(stage), stage.Pattern())
+		}
+		report.WriteString(fmt.Sprintf("%d %s [%s]\n", comp.ID, strings.Join(hits, "-"), strings.Join(stages, ", ")))
+	}
+	return report.String()
+}
+
+func (ep *EventProcessor) formatDeparture(stage models.ShootingStage) string {
+	if stage.Departure.IsZero() {
+		return ""
+	}
+	return ep.clock.Format(stage.Departure)
+}
+
+// rangeTimeReport returns the Range time section of the final report. Competitors who left at
+// least one firing range are ranked by their average range time, with every stage as
+// {range time, shooting time}:
+//
+//	[average] id [{range time, shooting time}, ...]
+//
+// Then come the field's average {range time, shooting time} and best {range time, competitor}
+// of every stage. Times of stages not finished, or shot without a hit, are left empty.
+func (ep *EventProcessor) rangeTimeReport() string {
+	type entry struct {
+		comp    *models.Competitor
+		average time.Duration
+	}
+	var entries []entry
+	stages := 0
+	for _, comp := range ep.Competitors {
+		if average, ok := averageRangeTime(comp); ok {
+			entries = append(entries, entry{comp: comp, average: average})
+			stages = max(stages, len(comp.Stages))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].average == entries[j].average {
+			return entries[i].comp.ID < entries[j].comp.ID
+		}
+		return entries[i].average < entries[j].average
+	})
+
+	var report strings.Builder
+	report.WriteString("\nRange time:\n")
+	for _, entry := range entries {
+		splits := make([]string, len(entry.comp.Stages))
+		for i, stage := range entry.comp.Stages {
+			splits[i] = fmt.Sprintf("{%s, %s}", ep.formatSplit(stage.RangeTime), ep.formatSplit(stage.ShootingTime))
+		}
+		report.WriteString(fmt.Sprintf("[%s] %d [%s]\n",
+			ep.clock.FormatDurationString(entry.average), entry.comp.ID, strings.Join(splits, ", ")))
+	}
+
+	averages := make([]string, stages)
+	bests := make([]string, stages)
+	for i := 0; i < stages; i++ {
+		var rangeTotal, shootingTotal time.Duration
+		var ranges, shootings int
+		var best *models.Competitor
+		for _, entry := range entries {
+			if i >= len(entry.comp.Stages) || entry.comp.Stages[i].Departure.IsZero() {
+				continue
+			}
+			stage := entry.comp.Stages[i]
+			rangeTotal += stage.RangeTime
+			ranges++
+			if stage.ShootingTime > 0 {
+				shootingTotal += stage.ShootingTime
+				shootings++
+			}
+			if best == nil || stage.RangeTime < best.Stages[i].RangeTime {
+				best = entry.comp
+			}
+		}
+
+		averages[i] = fmt.Sprintf("{%s, %s}", ep.formatSplit(average(rangeTotal, ranges)), ep.formatSplit(average(shootingTotal, shootings)))
+		if best == nil {
+			bests[i] = "{,}"
+		} else {
+			bests[i] = fmt.Sprintf("{%s, %d}", ep.formatSplit(best.Stages[i].RangeTime), best.ID)
+		}
+	}
+	report.WriteString("Average [" + strings.Join(averages, ", ") + "]\n")
+	report.WriteString("Best [" + strings.Join(bests, ", ") + "]\n")
+	return report.String()
+}
+
+// averageRangeTime returns the competitor's average time over the firing ranges they left.
+func averageRangeTime(comp *models.Competitor) (time.Duration, bool) {
+	var total time.Duration
+	left := 0
+	for _, stage := range comp.Stages {
+		if !stage.Departure.IsZero() {
+			total += stage.RangeTime
+			left++
+		}
+	}
+	return average(total, left), left > 0
+}
+
+func average(total time.Duration, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return total / time.Duration(n)
+}
+
+// formatSplit formats a stage time, leaving it empty if it is not known.
+func (ep *EventProcessor) formatSplit(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return ep.clock.FormatDurationString(d)
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"yadro-biathlon/internal/models"
+)
+
+func TestShootingReport(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 2
+	processor.Config.FiringLines = 2
+	processor.ShowStages = true
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:10.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:12.000", "2"),
+		createTestEvent(models.ActionHit, 1, "09:35:14.000", "4"),
+		createTestEvent(models.ActionHit, 1, "09:35:16.000", "5"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:35:30.000", ""),
+		createTestEvent(models.ActionFinishedLap, 1, "09:45:00.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:50:00.000", "2"),
+		createTestEvent(models.ActionHit, 1, "09:50:10.000", "3"),
+		createTestEvent(models.ActionStartTimeSet, 2, "09:15:00.000", "09:31:00.000"),
+		createTestEvent(models.ActionStarted, 2, "09:31:01.000", ""),
+	}
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	stages := processor.Competitors[1].Stages
+	if len(stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %d", len(stages))
+	}
+	if stages[0].Misses() != 1 || stages[1].Misses() != 4 {
+		t.Errorf("Expected 1 and 4 misses, got %d and %d", stages[0].Misses(), stages[1].Misses())
+	}
+	if !stages[0].Departure.After(stages[0].Arrival) || !stages[1].Departure.IsZero() {
+		t.Errorf("Expected a departure only from the first stage, got %+v", stages)
+	}
+
+	section := report[strings.Index(report, "\nShooting:\n"):]
+	expected := "\nShooting:\n" +
+		"1 4-1 [{1, 1, prone, 09:35:00.000, 09:35:30.000, xx.xx}, {2, 2, standing, 09:50:00.000, , ..x..}]\n"
+	if section != expected {
+		t.Errorf("Expected section:\n%s\ngot:\n%s", expected, section)
+	}
+}
+
+func TestRangeTimeReport(t *testing.T) {
+	processor := createTestProcessor()
+	processor.Config.Laps = 2
+	processor.Config.FiringLines = 2
+	processor.ShowRangeTimes = true
+
+	events := []models.Event{
+		createTestEvent(models.ActionStartTimeSet, 1, "09:15:00.000", "09:30:00.000"),
+		createTestEvent(models.ActionStartTimeSet, 2, "09:15:00.000", "09:31:00.000"),
+		createTestEvent(models.ActionStartTimeSet, 3, "09:15:00.000", "09:32:00.000"),
+		createTestEvent(models.ActionStarted, 1, "09:30:01.000", ""),
+		createTestEvent(models.ActionStarted, 2, "09:31:01.000", ""),
+		createTestEvent(models.ActionStarted, 3, "09:32:01.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:35:00.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:10.000", "1"),
+		createTestEvent(models.ActionHit, 1, "09:35:20.000", "2"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:35:30.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 2, "09:36:00.000", "1"),
+		createTestEvent(models.ActionLeftFiringRange, 2, "09:36:20.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 3, "09:37:00.000", "1"),
+		createTestEvent(models.ActionFinishedLap, 1, "09:45:00.000", ""),
+		createTestEvent(models.ActionOnFiringRange, 1, "09:50:00.000", "2"),
+		createTestEvent(models.ActionHit, 1, "09:50:05.000", "3"),
+		createTestEvent(models.ActionLeftFiringRange, 1, "09:50:10.000", ""),
+	}
+
+	var report string
+	captureOutput(func() {
+		processor.ProcessEvents(events)
+		report = processor.GenerateReport()
+	})
+
+	stage := processor.Competitors[1].Stages[0]
+	if stage.RangeTime != 30*time.Second || stage.ShootingTime != 20*time.Second {
+		t.Errorf("Expected 30s on the range and 20s shooting, got %v and %v", stage.RangeTime, stage.ShootingTime)
+	}
+
+	// Competitor 3 is still on the range and has no range time yet.
+	section := report[strings.Index(report, "\nRange time:\n"):]
+	expected := "\nRange time:\n" +
+		"[00:00:20.000] 1 [{00:00:30.000, 00:00:20.000}, {00:00:10.000, 00:00:05.000}]\n" +
+		"[00:00:20.000] 2 [{00:00:20.000, }]\n" +
+		"Average [{00:00:25.000, 00:00:20.000}, {00:00:10.000, 00:00:05.000}]\n" +
+		"Best [{00:00:20.000, 2}, {00:00:10.000, 1}]\n"
+	if section != expected {
+		t.Errorf("Expected section:\n%s\ngot:\n%s", expected, section)
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"yadro-biathlon/internal/config"
+)
+
+// fraction matches the fractional seconds of a time layout, e.g. ".000" or ",999999".
+var fraction = regexp.MustCompile(`05([.,])(0+|9+)`)
+
+// Clock parses and formats the clock times of a race
+// with the configured layout, output precision and time zone.
+type Clock struct {
+	Layout    string
+	Precision int
+	Location  *time.Location
+}
+
+// DefaultClock reads and writes times as "15:04:05.000" in UTC.
+var DefaultClock = Clock{Layout: config.TimeFormat, Precision: config.TimePrecision, Location: time.UTC}
+
+// NewClock returns the Clock described by the time settings of conf.
+func NewClock(conf config.Configuration) (Clock, error) {
+	loc, err := conf.Location()
+	if err != nil {
+		return DefaultClock, err
+	}
+	return Clock{Layout: conf.Layout(), Precision: conf.Precision(), Location: loc}, nil
+}
+
+// Parse removes square brackets and parses a clock time in the clock's zone.
+// Fractional seconds of another precision than the layout's are accepted as well.
+func (c Clock) Parse(s string) (time.Time, error) {
+	clean := strings.Trim(s, "[]")
+	t, err := time.ParseInLocation(c.Layout, clean, c.Location)
+	if err != nil {
+		seconds := fraction.ReplaceAllString(c.Layout, "05")
+		if t, secErr := time.ParseInLocation(seconds, clean, c.Location); secErr == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// Format writes t in the clock's zone and layout with Precision fractional digits.
+func (c Clock) Format(t time.Time) string {
+	return t.In(c.Location).Format(c.outputLayout())
+}
+
+// FormatTimeString wraps Format in square brackets for event logging.
+func (c Clock) FormatTimeString(t time.Time) string {
+	return "[" + c.Format(t) + "]"
+}
+
+// FormatDurationString formats d as HH:MM:SS followed by Precision fractional digits,
+// using the same decimal separator as the layout.
+func (c Clock) FormatDurationString(d time.Duration) string {
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	d -= s * time.Second
+
+	out := fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	if c.Precision > 0 {
+		unit := time.Duration(1)
+		for i := c.Precision; i < 9; i++ {
+			unit *= 10
+		}
+		out += fmt.Sprintf("%s%0*d", c.separator(), c.Precision, d/unit)
+	}
+	return out
+}
+
+func (c Clock) outputLayout() string {
+	digits := ""
+	if c.Precision > 0 {
+		digits = c.separator() + strings.Repeat("0", c.Precision)
+	}
+	if fraction.MatchString(c.Layout) {
+		return fraction.ReplaceAllString(c.Layout, "05"+digits)
+	}
+	return strings.Replace(c.Layout, "05", "05"+digits, 1)
+}
+
+func (c Clock) separator() string {
+	if m := fraction.FindStringSubmatch(c.Layout); m != nil {
+		return m[1]
+	}
+	return "."
+}
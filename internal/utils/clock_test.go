@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	"yadro-biathlon/internal/config"
+)
+
+func TestClockParseAndFormat(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	tests := []struct {
+		clock    Clock
+		input    string
+		expected string
+	}{
+		{DefaultClock, "[09:31:49.285]", "[09:31:49.285]"},
+		{DefaultClock, "[09:31:49.5]", "[09:31:49.500]"},
+		{Clock{Layout: "15:04:05,000", Precision: 3, Location: time.UTC}, "[09:31:49,285]", "[09:31:49,285]"},
+		{Clock{Layout: "15:04:05.000000", Precision: 6, Location: time.UTC}, "[09:31:49.285123]", "[09:31:49.285123]"},
+		{Clock{Layout: "15:04:05.000000", Precision: 3, Location: time.UTC}, "[09:31:49.285123]", "[09:31:49.285]"},
+		{Clock{Layout: "15:04:05", Precision: 2, Location: moscow}, "[09:31:49]", "[09:31:49.00]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.clock.Layout+" "+test.input, func(t *testing.T) {
+			parsed, err := test.clock.Parse(test.input)
+			if err != nil {
+				t.Fatalf("Parse(%s) failed: %v", test.input, err)
+			}
+			if parsed.Location() != test.clock.Location {
+				t.Errorf("Expected time in %v, got %v", test.clock.Location, parsed.Location())
+			}
+			if formatted := test.clock.FormatTimeString(parsed); formatted != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestClockFormatDurationString(t *testing.T) {
+	d := 29*time.Minute + 3*time.Second + 872_123_456*time.Nanosecond
+
+	tests := []struct {
+		clock    Clock
+		expected string
+	}{
+		{DefaultClock, "00:29:03.872"},
+		{Clock{Layout: "15:04:05,000", Precision: 3}, "00:29:03,872"},
+		{Clock{Layout: config.TimeFormat, Precision: 6}, "00:29:03.872123"},
+		{Clock{Layout: config.TimeFormat, Precision: 1}, "00:29:03.8"},
+	}
+
+	for _, test := range tests {
+		if formatted := test.clock.FormatDurationString(d); formatted != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, formatted)
+		}
+	}
+}
+
+func TestNewClock(t *testing.T) {
+	clock, err := NewClock(config.Configuration{TimeLayout: "15:04:05,000", TimePrecision: 2, TimeZone: "Europe/Berlin"})
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	if clock.Layout != "15:04:05,000" || clock.Precision != 2 || clock.Location.String() != "Europe/Berlin" {
+		t.Errorf("Unexpected clock %+v", clock)
+	}
+
+	if _, err := NewClock(config.Configuration{TimeZone: "Mars/Olympus"}); err == nil {
+		t.Error("Expected error for unknown time zone, got nil")
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"time"
+	"yadro-biathlon/internal/config"
+)
+
+// maxLateness is how far a clock time may fall behind the latest one and still be taken
+// as a late event of the same day; the reorder window is limited to it.
+const maxLateness = config.MaxLateness
+
+// Timeline places clock-only times (as parsed from "hh:mm:ss.mmm") onto calendar days.
+// It starts on the race date and moves to the next day whenever the clock wraps past
+// midnight, so durations across midnight stay positive.
+type Timeline struct {
+	last    time.Time
+	hasLast bool
+}
+
+// NewTimeline returns a Timeline whose first time is placed on the day of date.
+func NewTimeline(date time.Time) *Timeline {
+	return &Timeline{last: midnight(date)}
+}
+
+// Place returns clock time t on the day that follows the latest time placed so far.
+// The first time is placed on the race date. A time up to an hour earlier than the latest
+// one stays on its day as a late event; anything earlier is taken to be after midnight.
+func (tl *Timeline) Place(t time.Time) time.Time {
+	if !tl.hasLast {
+		tl.last = OnDay(t, tl.last)
+		tl.hasLast = true
+		return tl.last
+	}
+
+	placed := Near(t, tl.last)
+	if placed.After(tl.last) {
+		tl.last = placed
+	}
+	return placed
+}
+
+// Near returns clock time t on the day that places it within the 24 hours starting an hour before ref.
+// It is used for times given without a date, like a planned start next to its event.
+func Near(t, ref time.Time) time.Time {
+	placed := OnDay(t, ref)
+	if placed.Before(ref.Add(-maxLateness)) {
+		placed = placed.AddDate(0, 0, 1)
+	} else if !placed.Before(ref.Add(24*time.Hour - maxLateness)) {
+		placed = placed.AddDate(0, 0, -1)
+	}
+	return placed
+}
+
+// OnDay returns the clock time of t on the calendar day of ref.
+func OnDay(t, ref time.Time) time.Time {
+	return time.Date(ref.Year(), ref.Month(), ref.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), ref.Location())
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	"yadro-biathlon/internal/config"
+)
+
+func TestTimelinePlace(t *testing.T) {
+	date := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+	timeline := NewTimeline(date)
+
+	tests := []struct {
+		clock    string
+		expected time.Time
+	}{
+		{"23:50:00.000", time.Date(2024, time.December, 31, 23, 50, 0, 0, time.UTC)},
+		{"23:59:59.500", time.Date(2024, time.December, 31, 23, 59, 59, 500_000_000, time.UTC)},
+		{"00:00:01.000", time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC)},
+		{"23:59:59.900", time.Date(2024, time.December, 31, 23, 59, 59, 900_000_000, time.UTC)},
+		{"00:10:00.000", time.Date(2025, time.January, 1, 0, 10, 0, 0, time.UTC)},
+		{"13:00:00.000", time.Date(2025, time.January, 1, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		clock, _ := time.Parse(config.TimeFormat, test.clock)
+		placed := timeline.Place(clock)
+		if !placed.Equal(test.expected) {
+			t.Errorf("Place(%s): expected %v, got %v", test.clock, test.expected, placed)
+		}
+	}
+}
+
+func TestNear(t *testing.T) {
+	ref := time.Date(2024, time.December, 31, 23, 55, 0, 0, time.UTC)
+	clock, _ := time.Parse(config.TimeFormat, "00:05:00.000")
+
+	expected := time.Date(2025, time.January, 1, 0, 5, 0, 0, time.UTC)
+	if got := Near(clock, ref); !got.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}